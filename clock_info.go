@@ -0,0 +1,61 @@
+package time
+
+import (
+	"github.com/akm/time/internal"
+)
+
+// ClockSource is where the clock of Now comes from.
+type ClockSource string
+
+const (
+	ClockSourceReal ClockSource = "real"
+	// ClockSourceTest is a clock set by testtime.
+	ClockSourceTest ClockSource = "test"
+	// ClockSourceFaketime is a fake time set up from a spec which isn't read from a file, an environment variable or a header.
+	ClockSourceFaketime ClockSource = "faketime"
+	ClockSourceFile     ClockSource = "faketime file"
+	ClockSourceEnv      ClockSource = "faketime env"
+	// ClockSourceHeader is a fake time given by HTTP headers, cookies or gRPC metadata.
+	ClockSourceHeader ClockSource = "faketime header"
+)
+
+// ClockStatus describes the clock of Now, which ClockInfo returns.
+type ClockStatus struct {
+	Source ClockSource
+	// Spec is the faketime spec of the clock. It's empty for the real time and test clocks.
+	Spec string
+	// Ratio is how fast the clock proceeds against the real time. It's 1 for the real time,
+	// and 0 for a fake time which doesn't flow or a test clock.
+	Ratio float64
+	// Anchor is the real time when the fake time started from the time in Spec.
+	// It's zero unless the fake time flows.
+	Anchor Time
+	// Offset is the current time of the clock minus the real time.
+	Offset Duration
+}
+
+// Faked returns true unless the clock is the real time.
+func (s ClockStatus) Faked() bool {
+	return s.Source != ClockSourceReal
+}
+
+// ClockInfo returns the status of the clock of Now, which can be shown in health checks,
+// logs or metrics. Getting Offset isn't counted as a call by the clocks of
+// testtime.Increment and testtime.Sequence.
+func ClockInfo() ClockStatus {
+	info, ok := internal.CurrentInfo()
+	if !ok {
+		return ClockStatus{Source: ClockSourceReal, Ratio: 1}
+	}
+	source := ClockSource(info.Source)
+	if source == "" {
+		source = ClockSourceTest
+	}
+	return ClockStatus{
+		Source: source,
+		Spec:   info.Spec,
+		Ratio:  info.Ratio,
+		Anchor: info.Anchor,
+		Offset: internal.Peek().Sub(StdNow()),
+	}
+}
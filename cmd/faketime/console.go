@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+const consoleHelp = `Commands:
+  p, pause          freeze the fake time
+  r, resume         let the fake time flow again at the last ratio
+  +1h, -30m, +1d, +1M, +1y
+                    jump by the duration (s, m, h, d, M for months, y)
+  x<ratio>          change the ratio, e.g. x60, which takes effect on resume while paused
+  set <spec>        write spec
+  c, clear          go back to the real time
+  <empty>, s        show the status
+  ?, help           show this help
+  q, quit           quit
+`
+
+var jumpPattern = regexp.MustCompile(`^([+-])(\d+)([smhdMy])$`)
+
+// parseJump returns a function to move t by s such as "+1h" or "-1M".
+func parseJump(s string) (func(t time.Time) time.Time, bool) {
+	m := jumpPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+	n, err := strconv.Atoi(m[2])
+	if err != nil {
+		return nil, false
+	}
+	if m[1] == "-" {
+		n = -n
+	}
+	switch m[3] {
+	case "d":
+		return func(t time.Time) time.Time { return t.AddDate(0, 0, n) }, true
+	case "M":
+		return func(t time.Time) time.Time { return t.AddDate(0, n, 0) }, true
+	case "y":
+		return func(t time.Time) time.Time { return t.AddDate(n, 0, 0) }, true
+	}
+	d, err := time.ParseDuration(m[2] + m[3])
+	if err != nil {
+		return nil, false
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return func(t time.Time) time.Time { return t.Add(d) }, true
+}
+
+// console reads commands line by line and writes each change to the file,
+// which running services pick up on their next read.
+func (c *command) console(args []string) error {
+	if len(args) > 0 {
+		return usageError("console takes no arguments")
+	}
+
+	c.printStatus()
+	scanner := bufio.NewScanner(c.stdin)
+	for {
+		_, _ = fmt.Fprint(c.stdout, "> ")
+		if !scanner.Scan() {
+			_, _ = fmt.Fprintln(c.stdout)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+
+		var err error
+		switch {
+		case line == "" || line == "s" || line == "status":
+		case line == "?" || line == "help":
+			_, _ = fmt.Fprint(c.stdout, consoleHelp)
+			continue
+		case line == "q" || line == "quit" || line == "exit":
+			return nil
+		case line == "p" || line == "pause":
+			err = c.update(func(clock *faketime.Clock) error { clock.Pause(); return nil })
+		case line == "r" || line == "resume":
+			err = c.update(func(clock *faketime.Clock) error { clock.Resume(); return nil })
+		case line == "c" || line == "clear":
+			err = c.file.Delete()
+		case strings.HasPrefix(line, "set "):
+			err = c.set(strings.Fields(strings.TrimPrefix(line, "set ")))
+		case strings.HasPrefix(line, "x"):
+			var ratio float64
+			ratio, err = strconv.ParseFloat(strings.TrimPrefix(line, "x"), 64)
+			if err == nil {
+				err = c.update(func(clock *faketime.Clock) error { return clock.SetRatio(ratio) })
+			}
+		default:
+			jump, ok := parseJump(line)
+			if !ok {
+				_, _ = fmt.Fprintf(c.stdout, "unknown command: %s (? for help)\n", line)
+				continue
+			}
+			err = c.update(func(clock *faketime.Clock) error {
+				// Days, months and years are counted in the location of the app.
+				clock.Set(jump(clock.Now().In(time.CurrentLocation())))
+				return nil
+			})
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(c.stdout, "error: %v\n", err)
+			continue
+		}
+		c.printStatus()
+	}
+}
+
+// current returns nil without error if the file is not set.
+func (c *command) current() (*faketime.FakeTime, error) {
+	ft, err := c.load()
+	if err == errNotSet {
+		return nil, nil
+	}
+	return ft, err
+}
+
+// update applies fn to the clock of the file, or of the real time if the file is not set, and saves it.
+func (c *command) update(fn func(clock *faketime.Clock) error) error {
+	ft, err := c.current()
+	if err != nil {
+		return err
+	}
+	if ft == nil {
+		ft = &faketime.FakeTime{Time: time.StdNow(), Ratio: 1}
+	}
+	clock := faketime.NewClock(ft)
+	if err := fn(clock); err != nil {
+		return err
+	}
+
+	spec := clock.FakeTime().Format(c.file.Layout())
+	if _, err := faketime.Parse(spec, c.file.Layout()); err != nil {
+		return err
+	}
+	return c.file.SaveSpec(spec)
+}
+
+func (c *command) printStatus() {
+	realNow := time.StdNow().UTC().Format(c.file.Layout())
+	ft, err := c.current()
+	switch {
+	case err != nil:
+		_, _ = fmt.Fprintf(c.stdout, "real %s | error: %v\n", realNow, err)
+	case ft == nil:
+		_, _ = fmt.Fprintf(c.stdout, "real %s | fake (not set)\n", realNow)
+	default:
+		var mode string
+		switch {
+		case ft.Then != nil:
+			mode = "script"
+		case ft.Ratio != 0:
+			mode = fmt.Sprintf("x%v", ft.Ratio)
+		case ft.Increment != 0:
+			mode = fmt.Sprintf("+%v per call", ft.Increment)
+		case ft.Paused():
+			mode = fmt.Sprintf("paused, x%v", ft.PausedRatio)
+		default:
+			mode = "frozen"
+		}
+		fakeNow := faketime.NewClock(ft).Now().UTC().Format(c.file.Layout())
+		_, _ = fmt.Fprintf(c.stdout, "real %s | fake %s (%s)\n", realNow, fakeNow, mode)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+func TestParseJump(t *testing.T) {
+	base := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"+1h", base.Add(time.Hour)},
+		{"-30m", base.Add(-30 * time.Minute)},
+		{"+10s", base.Add(10 * time.Second)},
+		{"+1d", time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)},
+		{"+1M", time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)},
+		{"-1y", time.Date(2023, 1, 31, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		jump, ok := parseJump(tt.in)
+		if !ok {
+			t.Errorf("parseJump(%q) failed", tt.in)
+			continue
+		}
+		if got := jump(base); !got.Equal(tt.want) {
+			t.Errorf("parseJump(%q)(%v) = %v, want %v", tt.in, base, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"1h", "+h", "+1w", "+1.5h"} {
+		if _, ok := parseJump(in); ok {
+			t.Errorf("parseJump(%q) should fail", in)
+		}
+	}
+}
+
+func runConsole(t *testing.T, filePath, input string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	c := &command{
+		file:     faketime.NewFile(filePath, time.DateTime),
+		provider: faketime.NewFileProvider(filePath),
+		stdin:    strings.NewReader(input),
+		stdout:   &stdout,
+		stderr:   &stderr,
+	}
+	if err := c.console(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return stdout.String()
+}
+
+func TestCommand_Console(t *testing.T) {
+	readFile := func(t *testing.T, filePath string) string {
+		t.Helper()
+		content, err := os.ReadFile(filePath)
+		if os.IsNotExist(err) {
+			return ""
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	t.Run("jumps while frozen", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "faketime.txt")
+		if err := os.WriteFile(filePath, []byte("2024-12-31 23:59:00 x60"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		out := runConsole(t, filePath, "p\n+1h\n+1M\n-1d\nx0\nx2\nunknown\nq\n")
+
+		if got, want := readFile(t, filePath), "2025-01-31 00:59:00 x2 paused"; got != want {
+			t.Errorf("file = %q, want %q", got, want)
+		}
+		for _, want := range []string{
+			"fake 2024-12-31 23:59:00 (paused, x60)",
+			"fake 2025-01-01 00:59:00 (paused, x60)",
+			"fake 2025-02-01 00:59:00 (paused, x60)",
+			"fake 2025-01-31 00:59:00 (paused, x60)",
+			"error: ratio must be positive: 0",
+			"fake 2025-01-31 00:59:00 (paused, x2)",
+			"unknown command: unknown",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output should contain %q, output:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("jumps by months in the location of the app", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "faketime.txt")
+		// 2024-02-01 05:00:00 in FixedLocation.
+		if err := os.WriteFile(filePath, []byte("2024-01-31 20:00:00"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		runConsole(t, filePath, "+1M\n")
+		// 2024-03-01 05:00:00 in FixedLocation.
+		if got, want := readFile(t, filePath), "2024-02-29 20:00:00"; got != want {
+			t.Errorf("file = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("resume restores the ratio", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "faketime.txt")
+		if err := os.WriteFile(filePath, []byte("2024-12-31 23:59:00 x60"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		runConsole(t, filePath, "p\nr\n")
+		if got := readFile(t, filePath); !strings.HasPrefix(got, "2024-12-31 23:59:") || !strings.Contains(got, " x60 since=") {
+			t.Errorf("file = %q, want 2024-12-31 23:59:xx x60 since=...", got)
+		}
+
+		runConsole(t, filePath, "set 2030-01-01 00:00:00\nr\n")
+		if got := readFile(t, filePath); !strings.HasPrefix(got, "2030-01-01 00:00:0") || !strings.Contains(got, " x1 since=") {
+			t.Errorf("file = %q, want 2030-01-01 00:00:0x x1 since=...", got)
+		}
+	})
+
+	t.Run("starts from the real time", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "faketime.txt")
+
+		out := runConsole(t, filePath, "\np\nclear\n")
+		if !strings.Contains(out, "fake (not set)") {
+			t.Errorf("output should show that fake time is not set, output:\n%s", out)
+		}
+		if !strings.Contains(out, " (paused, x1)") {
+			t.Errorf("output should show the paused time, output:\n%s", out)
+		}
+		if got := readFile(t, filePath); got != "" {
+			t.Errorf("file = %q, want no file", got)
+		}
+	})
+}
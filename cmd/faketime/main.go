@@ -0,0 +1,240 @@
+// Command faketime reads and writes the faketime file which faketime.FileProvider reads.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+const usage = `Usage: faketime [flags] <command> [args]
+
+Commands:
+  set <spec>       validate spec and write it to the file
+  get              print the spec in the file
+  now              print the effective fake time
+  clear            remove the file
+  validate [spec]  validate spec, or the spec in the file if omitted
+  advance <dur>    move the fake time in the file by dur such as 1h or -30m
+  run --spec <spec> [--libfaketime] -- <command> [args]
+                   run command with the fake time given by spec
+  console          control the fake time in the file interactively
+
+Flags:
+`
+
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+func usageError(format string, args ...any) error {
+	return &exitError{code: 2, err: fmt.Errorf(format, args...)}
+}
+
+var errNotSet = errors.New("faketime is not set")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("faketime", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	filePath := fs.String("file", "faketime.txt", "path to the faketime file")
+	layout := fs.String("layout", time.DateTime, "layout of the time in specs")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cmd := &command{
+		file:     faketime.NewFile(*filePath, *layout),
+		provider: faketime.NewFileProvider(*filePath),
+		stdin:    os.Stdin,
+		stdout:   stdout,
+		stderr:   stderr,
+	}
+
+	var err error
+	switch name, cmdArgs := fs.Arg(0), fs.Args()[1:]; name {
+	case "set":
+		err = cmd.set(cmdArgs)
+	case "get":
+		err = cmd.get(cmdArgs)
+	case "now":
+		err = cmd.now(cmdArgs)
+	case "clear":
+		err = cmd.clear(cmdArgs)
+	case "validate":
+		err = cmd.validate(cmdArgs)
+	case "advance":
+		err = cmd.advance(cmdArgs)
+	case "console":
+		err = cmd.console(cmdArgs)
+	case "run":
+		var code int
+		if code, err = cmd.run(cmdArgs); err == nil {
+			return code
+		}
+	default:
+		err = usageError("unknown command: %s", name)
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "faketime: %v\n", err)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			return exitErr.code
+		}
+		return 1
+	}
+	return 0
+}
+
+type command struct {
+	file     *faketime.File
+	provider *faketime.FileProvider
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+}
+
+func (c *command) load() (*faketime.FakeTime, error) {
+	s, err := c.provider.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if s == "" {
+		return nil, errNotSet
+	}
+	ft, err := faketime.Parse(s, c.file.Layout())
+	if err != nil {
+		return nil, err
+	}
+	if ft.Anchor.IsZero() {
+		// Follow the same timeline as services which read the file.
+		if ft.Anchor, err = c.provider.Anchor(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	return ft, nil
+}
+
+func (c *command) set(args []string) error {
+	// Specs contain spaces, so they don't have to be quoted.
+	spec := strings.TrimSpace(strings.Join(args, " "))
+	if spec == "" {
+		return usageError("set requires a spec")
+	}
+	spec, err := faketime.AnchorSpec(spec, c.file.Layout())
+	if err != nil {
+		return err
+	}
+	if err := c.file.SaveSpec(spec); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.stdout, spec)
+	return err
+}
+
+func (c *command) get(args []string) error {
+	if len(args) > 0 {
+		return usageError("get takes no arguments")
+	}
+	s, err := c.provider.Get(context.Background())
+	if err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+	_, err = fmt.Fprintln(c.stdout, s)
+	return err
+}
+
+func (c *command) now(args []string) error {
+	if len(args) > 0 {
+		return usageError("now takes no arguments")
+	}
+	ft, err := c.load()
+	if err != nil {
+		return err
+	}
+	return ft.Run(context.Background(), func(ctx context.Context) error {
+		_, err := fmt.Fprintln(c.stdout, time.Now().UTC().Format(c.file.Layout()))
+		return err
+	})
+}
+
+func (c *command) clear(args []string) error {
+	if len(args) > 0 {
+		return usageError("clear takes no arguments")
+	}
+	return c.file.Delete()
+}
+
+func (c *command) validate(args []string) error {
+	var ft *faketime.FakeTime
+	var err error
+	if spec := strings.TrimSpace(strings.Join(args, " ")); spec != "" {
+		ft, err = faketime.Parse(spec, c.file.Layout())
+	} else {
+		ft, err = c.load()
+	}
+	if err != nil {
+		return err
+	}
+
+	mode := "frozen"
+	if ft.Ratio != 0 {
+		mode = fmt.Sprintf("x%v", ft.Ratio)
+	}
+	_, err = fmt.Fprintf(c.stdout, "valid: %s (%s)\n", ft.Time.UTC().Format(c.file.Layout()), mode)
+	return err
+}
+
+func (c *command) advance(args []string) error {
+	if len(args) != 1 {
+		return usageError("advance requires a duration")
+	}
+	d, err := time.ParseDuration(args[0])
+	if err != nil {
+		return usageError("invalid duration: %v", err)
+	}
+
+	ft, err := c.load()
+	if err != nil {
+		return err
+	}
+	clock := faketime.NewClock(ft)
+	clock.Set(clock.Now().Add(d))
+
+	spec := clock.FakeTime().Format(c.file.Layout())
+	if err := c.file.SaveSpec(spec); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.stdout, spec)
+	return err
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // initial content of faketime.txt, empty for no file
+		args    []string
+	}{
+		{name: "no_command", args: []string{}},
+		{name: "unknown_command", args: []string{"unknown"}},
+		{name: "set", args: []string{"set", "2024-12-31", "23:59:00", "x60"}},
+		{name: "set_quoted", args: []string{"set", "2024-12-31 23:59:00 +"}},
+		{name: "set_layout", args: []string{"--layout", "2006-01-02T15:04:05Z07:00", "set", "2024-12-31T23:59:00+09:00"}},
+		{name: "set_invalid", args: []string{"set", "2024-12-31T23:59:00Z"}},
+		{name: "set_empty", args: []string{"set"}},
+		{name: "get", content: "2024-12-31 23:59:00 x60\n", args: []string{"get"}},
+		{name: "get_not_set", args: []string{"get"}},
+		{name: "now", content: "2024-12-31 23:59:00", args: []string{"now"}},
+		{name: "now_not_set", args: []string{"now"}},
+		{name: "now_invalid", content: "2024-12-31T23:59:00Z", args: []string{"now"}},
+		{name: "clear", content: "2024-12-31 23:59:00", args: []string{"clear"}},
+		{name: "clear_not_set", args: []string{"clear"}},
+		{name: "validate_file", content: "2024-12-31 23:59:00 x60", args: []string{"validate"}},
+		{name: "validate_arg", args: []string{"validate", "@2024-12-31", "23:59:00"}},
+		{name: "validate_invalid", args: []string{"validate", "2024-12-31 23:59:00 x0"}},
+		{name: "advance", content: "2024-12-31 23:59:00 x60", args: []string{"advance", "1h30m"}},
+		{name: "advance_script", content: "2024-12-31 23:00:00 for=1h; 2025-01-01 00:00:00 x2", args: []string{"advance", "30m"}},
+		{name: "advance_negative", content: "2024-03-01 00:00:00", args: []string{"advance", "-24h"}},
+		{name: "advance_invalid_duration", content: "2024-03-01 00:00:00", args: []string{"advance", "1d"}},
+		{name: "advance_not_set", args: []string{"advance", "1h"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			if tt.content != "" {
+				if err := os.WriteFile("faketime.txt", []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+
+			var got strings.Builder
+			fmt.Fprintf(&got, "$ faketime %s\n", strings.Join(tt.args, " "))
+			fmt.Fprintf(&got, "--- exit: %d\n", code)
+			fmt.Fprintf(&got, "--- stdout:\n%s", stdout.String())
+			fmt.Fprintf(&got, "--- stderr:\n%s", stderr.String())
+			if content, err := os.ReadFile("faketime.txt"); err == nil {
+				fmt.Fprintf(&got, "--- faketime.txt:\n%s\n", string(content))
+			} else {
+				fmt.Fprintf(&got, "--- faketime.txt: (none)\n")
+			}
+
+			// Anchors are the real time when specs are set.
+			output := anchorPattern.ReplaceAllString(got.String(), "since=<anchor>")
+
+			golden := filepath.Join(testdataDir, tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(output), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file, run with -update to create it: %v", err)
+			}
+			if output != string(want) {
+				t.Errorf("output mismatch, run with -update to update it\n--- got:\n%s\n--- want:\n%s", output, string(want))
+			}
+		})
+	}
+}
+
+var anchorPattern = regexp.MustCompile(`since=\S+`)
+
+// testdataDir is absolute because tests change the working directory.
+var testdataDir = func() string {
+	dir, err := filepath.Abs("testdata")
+	if err != nil {
+		panic(err)
+	}
+	return dir
+}()
+
+func TestRun_SetScript(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"set", "2024-03-30 23:00:00 x60 for=5m; 2024-03-31 01:59:00"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, stderr.String())
+	}
+
+	content, err := os.ReadFile("faketime.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The script is anchored when it's set.
+	if got := string(content); !strings.HasPrefix(got, "2024-03-30 23:00:00 x60 since=") || !strings.HasSuffix(got, " for=5m0s; 2024-03-31 01:59:00") {
+		t.Errorf("faketime.txt = %q", got)
+	}
+}
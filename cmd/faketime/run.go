@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	orig "time"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+// EnvLibfaketime is the environment variable which libfaketime reads.
+const EnvLibfaketime = "FAKETIME"
+
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// run starts a child process with the spec in faketime.EnvSpec and returns its exit code.
+func (c *command) run(args []string) (int, error) {
+	fs := flag.NewFlagSet("faketime run", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(c.stderr, "Usage: faketime run --spec <spec> [--libfaketime] -- <command> [args]")
+		fs.PrintDefaults()
+	}
+	spec := fs.String("spec", "", "spec given to the child process")
+	libfaketime := fs.Bool("libfaketime", false, "also set "+EnvLibfaketime+" for libfaketime")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0, nil
+		}
+		return 2, nil
+	}
+	if *spec == "" {
+		return 0, usageError("run requires --spec")
+	}
+	if fs.NArg() == 0 {
+		return 0, usageError("run requires a command")
+	}
+
+	ft, err := faketime.Parse(*spec, c.file.Layout())
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	cmd.Stdin = c.stdin
+	cmd.Stdout = c.stdout
+	cmd.Stderr = c.stderr
+	cmd.Env = append(os.Environ(),
+		faketime.EnvSpec+"="+*spec,
+		faketime.EnvEnabled+"=true",
+	)
+	if *libfaketime {
+		cmd.Env = append(cmd.Env, EnvLibfaketime+"="+libfaketimeSpec(ft))
+	}
+
+	// Signals are registered before the child starts so that none of them is lost.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			// Same as shells for processes killed by a signal.
+			return 128 + int(status.Signal()), nil
+		}
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// libfaketimeSpec returns ft in the format of libfaketime, which uses the local time zone.
+// Relative specs and anchors are resolved to the current fake time so that both clocks agree.
+func libfaketimeSpec(ft *faketime.FakeTime) string {
+	s := faketime.NewClock(ft).Now().In(orig.Local).Format(time.DateTime)
+	switch {
+	case ft.Increment != 0:
+		// libfaketime takes the increment in seconds.
+		return "@" + s + " i" + strconv.FormatFloat(ft.Increment.Seconds(), 'f', -1, 64)
+	case ft.Ratio == 0:
+		// An absolute time without "@" is frozen in libfaketime.
+		return s
+	case ft.Ratio == 1:
+		return "@" + s
+	default:
+		return "@" + s + " x" + strconv.FormatFloat(ft.Ratio, 'f', -1, 64)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/akm/time"
+)
+
+func buildHelper(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping test building a binary in short mode")
+	}
+	bin := filepath.Join(t.TempDir(), "helper")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	out, err := exec.Command("go", "build", "-o", bin, "./testdata/helper").CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to build helper: %v\n%s", err, out)
+	}
+	return bin
+}
+
+func TestRun_Run(t *testing.T) {
+	helper := buildHelper(t)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout []string
+		wantStderr string
+	}{
+		{
+			name:     "frozen spec",
+			args:     []string{"run", "--spec", "2024-12-31 23:59:00", "--", helper},
+			wantCode: 0,
+			wantStdout: []string{
+				"now: 2024-12-31 23:59:00",
+				"FAKETIME: \n",
+			},
+		},
+		{
+			name:     "libfaketime",
+			args:     []string{"run", "--spec", "2024-12-31 23:59:00 x60", "--libfaketime", "--", helper},
+			wantCode: 0,
+			wantStdout: []string{
+				"now: 2024-12-31 23:59:0",
+				"FAKETIME: @" + time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC).In(time.Local).Format(time.DateTime) + " x60\n",
+			},
+		},
+		{
+			name:     "libfaketime with frozen spec",
+			args:     []string{"run", "--spec", "2024-12-31 23:59:00", "--libfaketime", "--", helper},
+			wantCode: 0,
+			wantStdout: []string{
+				"FAKETIME: " + time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC).In(time.Local).Format(time.DateTime) + "\n",
+			},
+		},
+		{
+			name:     "libfaketime with increment",
+			args:     []string{"run", "--spec", "2024-12-31 23:59:00 i1ms", "--libfaketime", "--", helper},
+			wantCode: 0,
+			wantStdout: []string{
+				"now: 2024-12-31 23:59:00",
+				"FAKETIME: @" + time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC).In(time.Local).Format(time.DateTime) + " i0.001\n",
+			},
+		},
+		{
+			name:     "exit code is propagated",
+			args:     []string{"run", "--spec", "2024-12-31 23:59:00", "--", helper, "exit", "3"},
+			wantCode: 3,
+			wantStdout: []string{
+				"now: 2024-12-31 23:59:00",
+			},
+		},
+		{
+			name:       "invalid spec",
+			args:       []string{"run", "--spec", "2024-12-31T23:59:00Z", "--", helper},
+			wantCode:   1,
+			wantStderr: "invalid faketime file content",
+		},
+		{
+			name:       "without spec",
+			args:       []string{"run", "--", helper},
+			wantCode:   2,
+			wantStderr: "run requires --spec",
+		},
+		{
+			name:       "without command",
+			args:       []string{"run", "--spec", "2024-12-31 23:59:00"},
+			wantCode:   2,
+			wantStderr: "run requires a command",
+		},
+		{
+			name:       "command not found",
+			args:       []string{"run", "--spec", "2024-12-31 23:59:00", "--", filepath.Join(t.TempDir(), "not-found")},
+			wantCode:   1,
+			wantStderr: "not-found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d, stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			for _, want := range tt.wantStdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout should contain %q, stdout: %s", want, stdout.String())
+				}
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr should contain %q, stderr: %s", tt.wantStderr, stderr.String())
+			}
+		})
+	}
+}
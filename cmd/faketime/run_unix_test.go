@@ -0,0 +1,59 @@
+//go:build unix
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+// readyWriter closes ready when "ready" is written.
+type readyWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	ready chan struct{}
+	once  sync.Once
+}
+
+func (w *readyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, err := w.buf.Write(p)
+	if strings.Contains(w.buf.String(), "ready\n") {
+		w.once.Do(func() { close(w.ready) })
+	}
+	return n, err
+}
+
+func (w *readyWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestRun_Run_ForwardsSignals(t *testing.T) {
+	helper := buildHelper(t)
+
+	stdout := &readyWriter{ready: make(chan struct{})}
+	var stderr bytes.Buffer
+	codes := make(chan int)
+	go func() {
+		codes <- run([]string{"run", "--spec", "2024-12-31 23:59:00", "--", helper, "wait"}, stdout, &stderr)
+	}()
+
+	<-stdout.ready
+	// run receives the signal instead of the test process because it's registered with signal.Notify.
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := <-codes; code != 42 {
+		t.Errorf("exit code = %d, want 42, stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "got terminated") {
+		t.Errorf("child should receive SIGTERM, stdout: %s", stdout.String())
+	}
+}
@@ -0,0 +1,44 @@
+// Command helper is run by the tests of `faketime run` as a child process.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+func main() {
+	err := faketime.NewRunner(faketime.NewEnvProvider(faketime.EnvSpec), time.DateTime).Start(context.Background(), func(ctx context.Context) error {
+		fmt.Println("now:", time.Now().UTC().Format(time.DateTime))
+		fmt.Println("FAKETIME:", os.Getenv("FAKETIME"))
+
+		if len(os.Args) < 2 {
+			return nil
+		}
+		switch os.Args[1] {
+		case "exit":
+			code, err := strconv.Atoi(os.Args[2])
+			if err != nil {
+				return err
+			}
+			os.Exit(code)
+		case "wait":
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, syscall.SIGTERM)
+			fmt.Println("ready")
+			fmt.Println("got", <-signals)
+			os.Exit(42)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
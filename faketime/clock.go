@@ -0,0 +1,177 @@
+package faketime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	orig "time"
+
+	"github.com/akm/time"
+	"github.com/akm/time/internal"
+)
+
+// Clock is an active fake clock. Pause, Resume, SetRatio and Set change how it proceeds
+// from the current fake time, so the timeline stays continuous. They end a script.
+// Its state can be persisted with FakeTime and Format.
+type Clock struct {
+	mu sync.Mutex
+	ft FakeTime
+	// calls counts the calls of Now for Increment.
+	calls atomic.Int64
+}
+
+// NewClock returns a Clock which starts as ft.
+// If ft.Anchor is zero, the current real time is used.
+func NewClock(ft *FakeTime) *Clock {
+	c := &Clock{ft: *ft}
+	if c.ft.Anchor.IsZero() {
+		c.ft.Anchor = orig.Now()
+	}
+	return c
+}
+
+// Now returns the current fake time in the same way as time.Now while the clock is set up.
+func (c *Clock) Now() time.Time {
+	return c.now().In(time.CurrentLocation())
+}
+
+func (c *Clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ft.Increment != 0 {
+		return c.ft.Time.Add(time.Duration(c.calls.Add(1)-1) * c.ft.Increment)
+	}
+	return c.ft.at(c.ft.Anchor, orig.Now())
+}
+
+// peek returns the time which now returns next without counting the call for Increment.
+func (c *Clock) peek() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ft.Increment != 0 {
+		return c.ft.Time.Add(time.Duration(c.calls.Load()) * c.ft.Increment)
+	}
+	return c.ft.at(c.ft.Anchor, orig.Now())
+}
+
+// FakeTime returns the state of the clock.
+// With Increment, Time is the one which the next call of Now returns.
+func (c *Clock) FakeTime() *FakeTime {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ft := c.ft
+	ft.Time = ft.Time.Add(time.Duration(c.calls.Load()) * ft.Increment)
+	return &ft
+}
+
+// Ratio returns the ratio at which the clock flows now, which is the one of the current step of a script.
+// It's 0 while the clock is frozen, paused or incremented.
+func (c *Clock) Ratio() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	step, _ := c.ft.stepAt(c.ft.Anchor, orig.Now())
+	return step.Ratio
+}
+
+// PausedRatio returns the ratio at which the clock resumes if it's paused now, otherwise 0.
+func (c *Clock) PausedRatio() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	step, _ := c.ft.stepAt(c.ft.Anchor, orig.Now())
+	if step.Ratio != 0 {
+		return 0
+	}
+	return step.PausedRatio
+}
+
+// Setup makes time.Now return the time of the clock until the returned function is called.
+func (c *Clock) Setup(ctx context.Context) func() {
+	return internal.SetNowFunc(c.now, internal.Override{
+		Owner: c.FakeTime().owner(),
+		Peek:  c.peek,
+		Info: func() internal.Info {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.ft.info(c.ft.Anchor)
+		},
+	})
+}
+
+// rebase moves the anchor to the current real time keeping the fake time at that moment.
+// A script ends at the step of the moment. It must be called with the lock held.
+func (c *Clock) rebase() {
+	real := orig.Now()
+	step, _ := c.ft.stepAt(c.ft.Anchor, real)
+	c.ft.Time = c.ft.at(c.ft.Anchor, real).Add(time.Duration(c.calls.Swap(0)) * c.ft.Increment)
+	c.ft.Ratio, c.ft.PausedRatio = step.Ratio, step.PausedRatio
+	c.ft.Then, c.ft.For = nil, 0
+	c.ft.Anchor = real
+	c.ft.Spec = ""
+}
+
+// endScript rebases a script at its current step, so that Ratio is the one of the step.
+// It must be called with the lock held.
+func (c *Clock) endScript() {
+	if c.ft.Then != nil {
+		c.rebase()
+	}
+}
+
+// Pause stops the clock at the current fake time. It does nothing if the clock is not flowing.
+func (c *Clock) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endScript()
+	if c.ft.Ratio == 0 {
+		return
+	}
+	c.rebase()
+	c.ft.Ratio, c.ft.PausedRatio = 0, c.ft.Ratio
+}
+
+// Resume lets the clock flow from the current fake time at the ratio it had before Pause,
+// or at 1 for a clock frozen or incremented from the start. It does nothing if the clock is flowing.
+func (c *Clock) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endScript()
+	if c.ft.Ratio != 0 {
+		return
+	}
+	ratio := c.ft.PausedRatio
+	if ratio == 0 {
+		ratio = 1.0
+	}
+	c.rebase()
+	c.ft.Ratio, c.ft.PausedRatio, c.ft.Increment = ratio, 0, 0
+}
+
+// SetRatio changes the ratio from the current fake time.
+// If the clock is frozen or paused, the ratio is used when it resumes.
+// An incremented clock starts flowing at the ratio.
+func (c *Clock) SetRatio(ratio float64) error {
+	if ratio <= 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidRatio, ratio)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rebase()
+	switch {
+	case c.ft.Increment != 0:
+		c.ft.Ratio, c.ft.Increment = ratio, 0
+	case c.ft.Ratio == 0:
+		c.ft.PausedRatio = ratio
+	default:
+		c.ft.Ratio = ratio
+	}
+	return nil
+}
+
+// Set moves the clock to t. It keeps flowing at the same ratio if it's flowing.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rebase()
+	c.ft.Time = t
+}
@@ -0,0 +1,248 @@
+package faketime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akm/time"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	// The clock has been flowing at x60 for a minute, so it's an hour later in the fake time.
+	c := NewClock(&FakeTime{Time: start, Ratio: 60, Anchor: time.StdNow().Add(-time.Minute)})
+
+	assertNear := func(t *testing.T, got, want time.Time) {
+		t.Helper()
+		if diff := got.Sub(want); diff < 0 || diff > 10*time.Second {
+			t.Errorf("Now() = %v, want %v (+10s)", got, want)
+		}
+	}
+	assertNear(t, c.Now(), start.Add(time.Hour))
+
+	c.Pause()
+	paused := c.Now()
+	assertNear(t, paused, start.Add(time.Hour))
+	if ft := c.FakeTime(); ft.Ratio != 0 || ft.PausedRatio != 60 || !ft.Paused() {
+		t.Errorf("FakeTime() = %+v, want paused at x60", ft)
+	}
+	if got := c.Now(); !got.Equal(paused) {
+		t.Errorf("Now() = %v, want %v while paused", got, paused)
+	}
+
+	if err := c.SetRatio(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ft := c.FakeTime(); ft.Ratio != 0 || ft.PausedRatio != 2 {
+		t.Errorf("FakeTime() = %+v, want paused at x2", ft)
+	}
+
+	c.Resume()
+	assertNear(t, c.Now(), paused)
+	if ft := c.FakeTime(); ft.Ratio != 2 || ft.PausedRatio != 0 || !ft.Time.Equal(paused) {
+		t.Errorf("FakeTime() = %+v, want flowing at x2 from %v", ft, paused)
+	}
+
+	target := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.Set(target)
+	assertNear(t, c.Now(), target)
+	if ft := c.FakeTime(); ft.Ratio != 2 {
+		t.Errorf("Ratio = %v, want 2 after Set", ft.Ratio)
+	}
+
+	if err := c.SetRatio(0); !errors.Is(err, ErrInvalidRatio) {
+		t.Errorf("SetRatio(0) error = %v, want %v", err, ErrInvalidRatio)
+	}
+}
+
+func TestClock_Resume_Frozen(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	c := NewClock(&FakeTime{Time: start})
+
+	c.Pause()
+	if ft := c.FakeTime(); ft.Paused() {
+		t.Errorf("Pause() should do nothing for a frozen clock, got %+v", ft)
+	}
+
+	c.Resume()
+	if ft := c.FakeTime(); ft.Ratio != 1 || !ft.Time.Equal(start) {
+		t.Errorf("FakeTime() = %+v, want flowing at x1 from %v", ft, start)
+	}
+}
+
+func TestClock_Format_Continuous(t *testing.T) {
+	layout := "2006-01-02 15:04:05"
+	c := NewClock(&FakeTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), Ratio: 60})
+	c.Set(time.Date(2024, 1, 2, 15, 4, 5, 750_000_000, time.UTC))
+
+	before := c.Now()
+	parsed, err := Parse(c.FakeTime().Format(layout), layout)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The fraction dropped by the layout is moved to the anchor.
+	if diff := NewClock(parsed).Now().Sub(before); diff < 0 || diff > time.Second {
+		t.Errorf("Now() after persisting = %v, want %v (+1s)", NewClock(parsed).Now(), before)
+	}
+}
+
+func TestRun_Clock(t *testing.T) {
+	ft := &FakeTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), Ratio: 1}
+	err := ft.Run(context.Background(), func(ctx context.Context) error {
+		c, ok := ClockFromContext(ctx)
+		if !ok {
+			t.Fatal("ClockFromContext() should return the clock")
+		}
+		c.Pause()
+		now := time.Now()
+		if got := time.Now(); !got.Equal(now) {
+			t.Errorf("time.Now() = %v, want %v while paused", got, now)
+		}
+		if got, _ := FromContext(ctx); !got.Paused() {
+			t.Errorf("FromContext() = %+v, want paused", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := ClockFromContext(NewContext(context.Background(), ft)); ok {
+		t.Error("ClockFromContext() should return false for a context from NewContext")
+	}
+}
+
+func TestClock_Increment(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	ft := &FakeTime{Time: start, Increment: time.Millisecond}
+
+	err := ft.Run(context.Background(), func(ctx context.Context) error {
+		for i := range 3 {
+			if got, want := time.Now(), start.Add(time.Duration(i)*time.Millisecond); !got.Equal(want) {
+				t.Errorf("call %d: time.Now() = %v, want %v", i, got, want)
+			}
+		}
+		c, _ := ClockFromContext(ctx)
+		if got := c.FakeTime(); !got.Time.Equal(start.Add(3*time.Millisecond)) || got.Increment != time.Millisecond {
+			t.Errorf("FakeTime() = %+v, want the next time with the increment", got)
+		}
+
+		if err := c.SetRatio(1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := c.FakeTime(); got.Increment != 0 || got.Ratio != 1 || !got.Time.Equal(start.Add(3*time.Millisecond)) {
+			t.Errorf("FakeTime() = %+v, want flowing at x1 from the next time", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("Setup", func(t *testing.T) {
+		defer ft.Setup(context.Background())()
+		first, second := time.Now(), time.Now()
+		if !first.Equal(start) || !second.Equal(start.Add(time.Millisecond)) {
+			t.Errorf("time.Now() = %v, %v, want %v, %v", first, second, start, start.Add(time.Millisecond))
+		}
+	})
+}
+
+func TestClock_ClockInfo(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	ft, err := Parse("2024-01-02 15:04:05 x2", time.DateTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ft.Source = time.ClockSourceFile
+	c := NewClock(ft)
+	defer c.Setup(context.Background())()
+
+	info := time.ClockInfo()
+	if info.Source != time.ClockSourceFile || info.Spec != "2024-01-02 15:04:05 x2" || info.Ratio != 2 || info.Anchor.IsZero() {
+		t.Errorf("ClockInfo() = %+v, want the flowing clock from the file", info)
+	}
+	if want := start.Sub(time.StdNow()); info.Offset < want || info.Offset > want+10*time.Second {
+		t.Errorf("Offset = %v, want %v (+10s)", info.Offset, want)
+	}
+
+	c.Pause()
+	if info := time.ClockInfo(); info.Ratio != 0 || !info.Anchor.IsZero() {
+		t.Errorf("ClockInfo() = %+v, want the paused clock", info)
+	}
+}
+
+func TestClock_Ratio(t *testing.T) {
+	tests := []struct {
+		name            string
+		spec            string
+		wantRatio       float64
+		wantPausedRatio float64
+	}{
+		{name: "frozen", spec: "2024-01-01 00:00:00"},
+		{name: "flowing", spec: "2024-01-01 00:00:00 x60", wantRatio: 60},
+		{name: "paused", spec: "2024-01-01 00:00:00 x60 paused", wantPausedRatio: 60},
+		{name: "incremented", spec: "2024-01-01 00:00:00 i1s"},
+		{name: "script at its first step", spec: "2024-01-01 00:00:00 x2 for=1h; 2024-06-01 00:00:00 x60", wantRatio: 2},
+		{name: "script at its second step", spec: "2024-01-01 00:00:00 x2 for=1ns; 2024-06-01 00:00:00 x60 paused", wantPausedRatio: 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft, err := Parse(tt.spec, time.DateTime)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			c := NewClock(ft)
+			time.Sleep(time.Millisecond)
+
+			if got := c.Ratio(); got != tt.wantRatio {
+				t.Errorf("Ratio() = %v, want %v", got, tt.wantRatio)
+			}
+			if got := c.PausedRatio(); got != tt.wantPausedRatio {
+				t.Errorf("PausedRatio() = %v, want %v", got, tt.wantPausedRatio)
+			}
+		})
+	}
+}
+
+func TestClock_Script_PauseResume(t *testing.T) {
+	t.Run("Pause at a flowing step after a frozen one", func(t *testing.T) {
+		ft, err := Parse("2024-01-01 00:00:00 for=1ns; 2024-06-01 00:00:00 x1", time.DateTime)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c := NewClock(ft)
+		time.Sleep(time.Millisecond)
+
+		c.Pause()
+		paused := c.Now()
+		time.Sleep(time.Millisecond)
+		if got := c.Now(); !got.Equal(paused) {
+			t.Errorf("Now() = %v, want %v while paused", got, paused)
+		}
+		if ft := c.FakeTime(); !ft.Paused() || ft.PausedRatio != 1 {
+			t.Errorf("FakeTime() = %+v, want paused at x1", ft)
+		}
+	})
+
+	t.Run("Resume at a frozen step after a flowing one", func(t *testing.T) {
+		ft, err := Parse("2024-01-01 00:00:00 x1 for=1ns; 2024-06-01 00:00:00", time.DateTime)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c := NewClock(ft)
+		time.Sleep(time.Millisecond)
+
+		c.Resume()
+		resumed := c.Now()
+		time.Sleep(time.Millisecond)
+		if got := c.Now(); !got.After(resumed) {
+			t.Errorf("Now() = %v, want after %v once resumed", got, resumed)
+		}
+		if ft := c.FakeTime(); ft.Ratio != 1 || ft.Then != nil {
+			t.Errorf("FakeTime() = %+v, want flowing at x1 without the script", ft)
+		}
+	})
+}
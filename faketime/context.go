@@ -0,0 +1,33 @@
+package faketime
+
+import (
+	"context"
+)
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, ft *FakeTime) context.Context {
+	return context.WithValue(ctx, contextKey{}, ft)
+}
+
+func newClockContext(ctx context.Context, c *Clock) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the fake time in ctx.
+// Under Run, it's the current state of the Clock.
+func FromContext(ctx context.Context) (*FakeTime, bool) {
+	switch v := ctx.Value(contextKey{}).(type) {
+	case *FakeTime:
+		return v, true
+	case *Clock:
+		return v.FakeTime(), true
+	}
+	return nil, false
+}
+
+// ClockFromContext returns the Clock which Run puts in ctx.
+func ClockFromContext(ctx context.Context) (*Clock, bool) {
+	c, ok := ctx.Value(contextKey{}).(*Clock)
+	return c, ok
+}
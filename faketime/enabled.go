@@ -0,0 +1,28 @@
+package faketime
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+// BuildTag is the build tag which enables faketime. It isn't "faketime", which the Go runtime
+// uses for the fake clock of the playground.
+const BuildTag = "akmfaketime"
+
+// EnvEnabled is the environment variable to enable faketime in binaries built without BuildTag.
+const EnvEnabled = "FAKETIME_ENABLED"
+
+var (
+	ErrNotEnabled = errors.New("faketime is not enabled")
+)
+
+// Enabled reports whether faketime may change the clock.
+// It's true when the binary is built with BuildTag or EnvEnabled is set to true.
+func Enabled() bool {
+	if buildTagEnabled {
+		return true
+	}
+	v, _ := strconv.ParseBool(os.Getenv(EnvEnabled))
+	return v
+}
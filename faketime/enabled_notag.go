@@ -0,0 +1,5 @@
+//go:build !akmfaketime
+
+package faketime
+
+const buildTagEnabled = false
@@ -0,0 +1,49 @@
+package faketime
+
+import (
+	"testing"
+
+	"github.com/akm/time"
+)
+
+func TestEnabled(t *testing.T) {
+	if buildTagEnabled {
+		t.Skip("faketime is enabled by the build tag")
+	}
+
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "", want: false},
+		{value: "false", want: false},
+		{value: "0", want: false},
+		{value: "yes", want: false},
+		{value: "true", want: true},
+		{value: "1", want: true},
+		{value: "TRUE", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(EnvEnabled, tt.value)
+			if got := Enabled(); got != tt.want {
+				t.Errorf("Enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnabled_BuildTag(t *testing.T) {
+	if !buildTagEnabled {
+		t.Skip("run with -tags " + BuildTag)
+	}
+	t.Setenv(EnvEnabled, "false")
+	if !Enabled() {
+		t.Error("Enabled() = false, want true with the build tag")
+	}
+	// The fake clock of the Go runtime starts in 2009.
+	if now := time.StdNow(); now.Year() < 2025 {
+		t.Errorf("time.StdNow() = %v, want the real time", now)
+	}
+}
@@ -6,36 +6,81 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	orig "time"
 
 	"github.com/akm/time"
-	"github.com/akm/time/testtime"
+	"github.com/akm/time/internal"
 )
 
 type FakeTime struct {
 	Time  time.Time
 	Ratio float64
+	// PausedRatio is the ratio to resume with when the fake time is paused.
+	// It's zero unless Ratio is zero.
+	PausedRatio float64
+	// Increment advances the fake time by itself on each call of time.Now from Time.
+	// It's zero unless Ratio and PausedRatio are zero.
+	Increment orig.Duration
+	// Anchor is the real time when the fake time is Time.
+	// If it's zero, the time when Setup is called is used.
+	Anchor orig.Time
+	// Then is the next step of a script, which starts when the real time
+	// For has passed since Anchor. Its own Anchor is not used.
+	Then *FakeTime
+	For  orig.Duration
+	// Spec is the string which Parse read.
+	Spec string
+	// Source is where Spec comes from, which time.ClockInfo reports.
+	// If it's empty, time.ClockSourceFaketime is reported.
+	Source time.ClockSource
 }
 
 var (
 	ErrInvalidFaketimeFileContent = errors.New("invalid faketime file content")
+	ErrInvalidRatio               = errors.New("ratio must be positive")
 )
 
+const (
+	optionPaused = "paused"
+	optionSince  = "since="
+	optionFor    = "for="
+)
+
+func isOption(s string) bool {
+	return s == "+" || strings.HasPrefix(s, "x") || strings.HasPrefix(s, "i") || s == optionPaused || strings.HasPrefix(s, optionSince) || strings.HasPrefix(s, optionFor)
+}
+
+// Parse reads a spec, which is a time followed by options separated by spaces.
+//
+//   - "+" or "x<ratio>" makes the fake time flow from the time at the ratio.
+//   - "paused" keeps the fake time at the time, and the ratio is used by Clock.Resume.
+//   - "i<duration>" advances the fake time by the duration on each call of time.Now.
+//   - "since=<RFC3339Nano>" is the real time when the fake time is the time, which is Anchor.
+//
+// A spec can also be a script of steps separated by newlines or ";". See parseScript.
 func Parse(s string, layout string) (*FakeTime, error) {
+	if isScript(s) {
+		return parseScript(s, layout)
+	}
+	ft, err := parseStep(s, layout)
+	if err != nil {
+		return nil, err
+	}
+	if ft.For != 0 {
+		return nil, fmt.Errorf("%w: %s needs a following step in file content: %s", ErrInvalidFaketimeFileContent, optionFor, s)
+	}
+	return ft, nil
+}
+
+func parseStep(s string, layout string) (*FakeTime, error) {
 	parts := strings.Split(s, " ")
-	var body string
-	var opt string
-	if len(parts) > 1 {
-		last := parts[len(parts)-1]
-		if last == "+" || strings.HasPrefix(last, "x") {
-			opt = last
-			body = strings.Join(parts[:len(parts)-1], " ")
-		} else {
-			body = s
-		}
-	} else {
-		body = s
+	var opts []string
+	for len(parts) > 1 && isOption(parts[len(parts)-1]) {
+		opts = append([]string{parts[len(parts)-1]}, opts...)
+		parts = parts[:len(parts)-1]
 	}
+	body := strings.Join(parts, " ")
 
 	var t time.Time
 	if strings.HasPrefix(body, "+") || strings.HasPrefix(body, "-") {
@@ -53,47 +98,224 @@ func Parse(s string, layout string) (*FakeTime, error) {
 		}
 	}
 
-	if opt == "" {
-		return &FakeTime{Time: t, Ratio: 0}, nil
+	ft := &FakeTime{Time: t, Spec: s}
+	var ratio float64
+	var paused bool
+	for _, opt := range opts {
+		switch {
+		case opt == optionPaused:
+			if paused {
+				return nil, fmt.Errorf("%w: duplicated option %q in file content: %s", ErrInvalidFaketimeFileContent, opt, s)
+			}
+			paused = true
+		case strings.HasPrefix(opt, optionSince):
+			if !ft.Anchor.IsZero() {
+				return nil, fmt.Errorf("%w: duplicated option %q in file content: %s", ErrInvalidFaketimeFileContent, opt, s)
+			}
+			anchor, err := orig.Parse(orig.RFC3339Nano, strings.TrimPrefix(opt, optionSince))
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to parse anchor from file content: %s, error: %v", ErrInvalidFaketimeFileContent, s, err)
+			}
+			ft.Anchor = anchor
+		case strings.HasPrefix(opt, optionFor):
+			if ft.For != 0 {
+				return nil, fmt.Errorf("%w: duplicated option %q in file content: %s", ErrInvalidFaketimeFileContent, opt, s)
+			}
+			d, err := orig.ParseDuration(strings.TrimPrefix(opt, optionFor))
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to parse step duration from file content: %s, error: %v", ErrInvalidFaketimeFileContent, s, err)
+			}
+			if d <= 0 {
+				return nil, fmt.Errorf("%w: step duration must be positive in file content: %s", ErrInvalidFaketimeFileContent, s)
+			}
+			ft.For = d
+		case strings.HasPrefix(opt, "i"):
+			if ft.Increment != 0 {
+				return nil, fmt.Errorf("%w: duplicated option %q in file content: %s", ErrInvalidFaketimeFileContent, opt, s)
+			}
+			d, err := orig.ParseDuration(strings.TrimPrefix(opt, "i"))
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to parse increment from file content: %s, error: %v", ErrInvalidFaketimeFileContent, s, err)
+			}
+			if d <= 0 {
+				return nil, fmt.Errorf("%w: increment must be positive in file content: %s", ErrInvalidFaketimeFileContent, s)
+			}
+			ft.Increment = d
+		default:
+			if ratio != 0 {
+				return nil, fmt.Errorf("%w: duplicated option %q in file content: %s", ErrInvalidFaketimeFileContent, opt, s)
+			}
+			var err error
+			ratio, err = parseRatio(opt, s)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	var ratioStr string
-	if strings.HasPrefix(opt, "x") {
-		ratioStr = strings.TrimPrefix(opt, "x")
-		if ratioStr == "" {
-			return nil, fmt.Errorf("%w: missing ratio after 'x' in file content: %s", ErrInvalidFaketimeFileContent, s)
+	if ft.Increment != 0 && (ratio != 0 || paused) {
+		return nil, fmt.Errorf("%w: increment cannot be used with a ratio in file content: %s", ErrInvalidFaketimeFileContent, s)
+	}
+	if paused {
+		if ratio == 0 {
+			ratio = 1.0
 		}
+		ft.PausedRatio = ratio
+	} else {
+		ft.Ratio = ratio
 	}
+	return ft, nil
+}
 
-	var ratio float64
+// parseRatio parses "+" or "x<ratio>" in the spec s.
+func parseRatio(opt, s string) (float64, error) {
+	if opt == "+" {
+		return 1.0, nil
+	}
+	ratioStr := strings.TrimPrefix(opt, "x")
 	if ratioStr == "" {
-		ratio = 1.0
-	} else {
-		var err error
-		ratio, err = strconv.ParseFloat(ratioStr, 64)
-		if err != nil {
-			return nil, fmt.Errorf("%w: failed to parse ratio from file content: %s, error: %v", ErrInvalidFaketimeFileContent, s, err)
+		return 0, fmt.Errorf("%w: missing ratio after 'x' in file content: %s", ErrInvalidFaketimeFileContent, s)
+	}
+	ratio, err := strconv.ParseFloat(ratioStr, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to parse ratio from file content: %s, error: %v", ErrInvalidFaketimeFileContent, s, err)
+	}
+	if ratio <= 0 {
+		return 0, fmt.Errorf("%w: ratio must be positive in file content: %s", ErrInvalidFaketimeFileContent, s)
+	}
+	return ratio, nil
+}
+
+// Format returns a spec which Parse reads back with the same layout.
+// The anchor is written for a flowing fake time or a script so that its timeline continues.
+func (ft *FakeTime) Format(layout string) string {
+	steps := []string{}
+	for step := ft; step != nil; step = step.Then {
+		s := step.Time.UTC().Format(layout)
+		switch {
+		case step.Ratio != 0:
+			s += " x" + strconv.FormatFloat(step.Ratio, 'f', -1, 64)
+		case step.PausedRatio != 0:
+			s += " x" + strconv.FormatFloat(step.PausedRatio, 'f', -1, 64) + " " + optionPaused
+		case step.Increment != 0:
+			s += " i" + step.Increment.String()
+		}
+		if step == ft && !ft.Anchor.IsZero() && (ft.Ratio != 0 || ft.Then != nil) {
+			anchor := ft.Anchor
+			// Shift the anchor by what the layout drops from the time, such as fractional seconds.
+			if t, err := time.Parse(layout, ft.Time.UTC().Format(layout)); err == nil && ft.Ratio != 0 {
+				anchor = anchor.Add(time.Duration(float64(t.Sub(ft.Time)) / ft.Ratio))
+			}
+			s += " " + optionSince + anchor.UTC().Format(orig.RFC3339Nano)
 		}
-		if ratio <= 0 {
-			return nil, fmt.Errorf("%w: ratio must be positive in file content: %s", ErrInvalidFaketimeFileContent, s)
+		if step.Then != nil {
+			s += " " + optionFor + step.For.String()
 		}
+		steps = append(steps, s)
+	}
+	return strings.Join(steps, scriptSeparator)
+}
+
+// AnchorSpec parses spec and returns it with the current real time as the anchor
+// if the fake time flows or is a script without an anchor, so that every reader
+// of a file with the spec follows the same timeline. Otherwise it returns spec as is.
+func AnchorSpec(spec, layout string) (string, error) {
+	ft, err := Parse(spec, layout)
+	if err != nil {
+		return "", err
+	}
+	if !ft.Anchor.IsZero() || (ft.Ratio == 0 && ft.Then == nil) {
+		return spec, nil
 	}
+	ft.Anchor = orig.Now()
+	return ft.Format(layout), nil
+}
+
+// Paused returns true if the fake time was paused by Clock.Pause or the "paused" option.
+func (ft *FakeTime) Paused() bool {
+	return ft.Ratio == 0 && ft.PausedRatio != 0
+}
+
+// at returns the fake time at the real time when the fake time started at anchor.
+func (ft *FakeTime) at(anchor, real orig.Time) time.Time {
+	step, anchor := ft.stepAt(anchor, real)
+	if step.Ratio == 0 {
+		return step.Time
+	}
+	elapsed := time.Duration(float64(real.Sub(anchor)) * step.Ratio)
+	return step.Time.Add(elapsed)
+}
 
-	return &FakeTime{Time: t, Ratio: ratio}, nil
+// stepAt returns the step of the script at the real time and when the step started.
+func (ft *FakeTime) stepAt(anchor, real orig.Time) (*FakeTime, orig.Time) {
+	step := ft
+	for step.Then != nil && real.Sub(anchor) >= step.For {
+		anchor = anchor.Add(step.For)
+		step = step.Then
+	}
+	return step, anchor
 }
 
 func (ft *FakeTime) Setup(ctx context.Context) func() {
-	if ft.Ratio == 0 {
-		return testtime.SetTime(&ft.Time)
+	if ft.Increment != 0 {
+		var calls atomic.Int64
+		return internal.SetNowFunc(func() time.Time {
+			return ft.Time.Add(time.Duration(calls.Add(1)-1) * ft.Increment)
+		}, internal.Override{
+			Owner: ft.owner(),
+			Peek:  func() time.Time { return ft.Time.Add(time.Duration(calls.Load()) * ft.Increment) },
+			Info:  func() internal.Info { return ft.info(ft.Anchor) },
+		})
+	}
+	if ft.Ratio == 0 && ft.Then == nil {
+		return internal.SetNowFunc(func() time.Time { return ft.Time }, internal.Override{
+			Owner: ft.owner(),
+			Info:  func() internal.Info { return ft.info(ft.Anchor) },
+		})
+	}
+	t0 := ft.Anchor
+	if t0.IsZero() {
+		t0 = orig.Now()
 	}
-	t0 := orig.Now()
-	return testtime.SetTimeFunc(func() time.Time {
-		elapsed := time.Duration(float64(orig.Since(t0)) * ft.Ratio)
-		return ft.Time.Add(elapsed)
+	return internal.SetNowFunc(func() time.Time {
+		return ft.at(t0, orig.Now())
+	}, internal.Override{
+		Owner: ft.owner(),
+		Info:  func() internal.Info { return ft.info(t0) },
 	})
 }
 
+// spec returns Spec, or the one which Format writes if it's empty.
+func (ft *FakeTime) spec() string {
+	if ft.Spec != "" {
+		return ft.Spec
+	}
+	return ft.Format(orig.RFC3339Nano)
+}
+
+// owner describes the fake time in the overrides of time.Now.
+func (ft *FakeTime) owner() string {
+	return "faketime " + strconv.Quote(ft.spec())
+}
+
+// info describes the fake time started at anchor for time.ClockInfo.
+func (ft *FakeTime) info(anchor orig.Time) internal.Info {
+	source := ft.Source
+	if source == "" {
+		source = time.ClockSourceFaketime
+	}
+	step, _ := ft.stepAt(anchor, orig.Now())
+	if step.Ratio == 0 {
+		anchor = orig.Time{}
+	}
+	return internal.Info{Source: string(source), Spec: ft.spec(), Ratio: step.Ratio, Anchor: anchor}
+}
+
+// Run calls fn with the fake time.
+// The context given to fn holds the Clock, which ClockFromContext returns,
+// and FromContext returns the fake time of the Clock with its Anchor.
 func (ft *FakeTime) Run(ctx context.Context, fn func(context.Context) error) error {
-	defer ft.Setup(ctx)()
-	return fn(ctx)
+	c := NewClock(ft)
+	defer c.Setup(ctx)()
+	return fn(newClockContext(ctx, c))
 }
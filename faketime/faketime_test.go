@@ -6,7 +6,7 @@ import (
 	"testing"
 
 	"github.com/akm/time"
-	"github.com/akm/time/testtime"
+	"github.com/akm/time/internal"
 )
 
 func TestParse(t *testing.T) {
@@ -14,14 +14,17 @@ func TestParse(t *testing.T) {
 
 	// Set a fixed "now" time for tests involving relative times
 	baseTime := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
-	defer testtime.SetTime(&baseTime)()
+	defer internal.SetNowFunc(func() time.Time { return baseTime }, internal.Override{Owner: "TestParse"})()
 
 	tests := []struct {
-		name      string
-		input     string
-		wantTime  time.Time
-		wantRatio float64
-		wantErr   error
+		name       string
+		input      string
+		wantTime   time.Time
+		wantRatio  float64
+		wantPaused float64
+		wantAnchor time.Time
+		wantInc    time.Duration
+		wantErr    error
 	}{
 		{
 			name:      "absolute time without prefix",
@@ -77,6 +80,51 @@ func TestParse(t *testing.T) {
 			wantTime:  baseTime.Add(2 * time.Hour),
 			wantRatio: 3.0,
 		},
+		{
+			name:       "paused with ratio",
+			input:      "2024-01-02 15:04:05 x2 paused",
+			wantTime:   time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+			wantPaused: 2.0,
+		},
+		{
+			name:       "paused without ratio",
+			input:      "2024-01-02 15:04:05 paused",
+			wantTime:   time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+			wantPaused: 1.0,
+		},
+		{
+			name:       "anchor",
+			input:      "2024-01-02 15:04:05 x2 since=2024-06-15T03:00:00.5Z",
+			wantTime:   time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+			wantRatio:  2.0,
+			wantAnchor: time.Date(2024, 6, 15, 3, 0, 0, 500_000_000, time.UTC),
+		},
+		{
+			name:     "increment",
+			input:    "2024-01-02 15:04:05 i1ms",
+			wantTime: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+			wantInc:  time.Millisecond,
+		},
+		{
+			name:    "increment with ratio",
+			input:   "2024-01-02 15:04:05 x2 i1ms",
+			wantErr: ErrInvalidFaketimeFileContent,
+		},
+		{
+			name:    "zero increment",
+			input:   "2024-01-02 15:04:05 i0s",
+			wantErr: ErrInvalidFaketimeFileContent,
+		},
+		{
+			name:    "invalid anchor",
+			input:   "2024-01-02 15:04:05 x2 since=yesterday",
+			wantErr: ErrInvalidFaketimeFileContent,
+		},
+		{
+			name:    "duplicated ratio",
+			input:   "2024-01-02 15:04:05 x2 x3",
+			wantErr: ErrInvalidFaketimeFileContent,
+		},
 		{
 			name:    "invalid duration",
 			input:   "+invalid",
@@ -133,6 +181,15 @@ func TestParse(t *testing.T) {
 			if got.Ratio != tt.wantRatio {
 				t.Errorf("Ratio = %v, want %v", got.Ratio, tt.wantRatio)
 			}
+			if got.PausedRatio != tt.wantPaused {
+				t.Errorf("PausedRatio = %v, want %v", got.PausedRatio, tt.wantPaused)
+			}
+			if got.Increment != tt.wantInc {
+				t.Errorf("Increment = %v, want %v", got.Increment, tt.wantInc)
+			}
+			if !got.Anchor.Equal(tt.wantAnchor) {
+				t.Errorf("Anchor = %v, want %v", got.Anchor, tt.wantAnchor)
+			}
 		})
 	}
 }
@@ -205,6 +262,45 @@ func TestFakeTime_Setup(t *testing.T) {
 			t.Error("time should be restored after cleanup")
 		}
 	})
+
+	t.Run("cleanups can be called out of order", func(t *testing.T) {
+		ft1 := FakeTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)}
+		ft2 := FakeTime{Time: time.Date(2024, 3, 4, 15, 4, 5, 0, time.UTC)}
+		ctx := context.Background()
+
+		cleanup1 := ft1.Setup(ctx)
+		cleanup2 := ft2.Setup(ctx)
+
+		// Concurrent requests of the middlewares finish in any order.
+		cleanup1()
+		if now := time.Now(); !now.Equal(ft2.Time) {
+			t.Errorf("time.Now() = %v, want %v", now, ft2.Time)
+		}
+		cleanup2()
+		if owners := internal.NowFuncOwners(); len(owners) != 0 {
+			t.Errorf("overrides are left: %v", owners)
+		}
+	})
+
+	t.Run("clock change hooks are called", func(t *testing.T) {
+		ft := FakeTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)}
+
+		var news []time.Time
+		defer time.OnClockChange(func(old, new time.Time) {
+			news = append(news, new)
+		})()
+
+		ft.Setup(context.Background())()
+		if len(news) != 2 {
+			t.Fatalf("hooks are called %d times, want 2", len(news))
+		}
+		if !news[0].Equal(ft.Time) {
+			t.Errorf("new time on setup = %v, want %v", news[0], ft.Time)
+		}
+		if news[1].Equal(ft.Time) {
+			t.Error("new time on cleanup should be the real time")
+		}
+	})
 }
 
 func TestFakeTime_Run(t *testing.T) {
@@ -275,3 +371,174 @@ func TestFakeTime_Run_ReturnsError(t *testing.T) {
 		t.Errorf("Run() error = %v, want %v", err, expectedErr)
 	}
 }
+
+func TestFakeTime_Format(t *testing.T) {
+	tests := []struct {
+		name     string
+		fakeTime FakeTime
+		layout   string
+		want     string
+	}{
+		{
+			name:     "frozen time",
+			fakeTime: FakeTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+			layout:   "2006-01-02 15:04:05",
+			want:     "2024-01-02 15:04:05",
+		},
+		{
+			name:     "time with ratio",
+			fakeTime: FakeTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), Ratio: 2},
+			layout:   "2006-01-02 15:04:05",
+			want:     "2024-01-02 15:04:05 x2",
+		},
+		{
+			name:     "time with fractional ratio",
+			fakeTime: FakeTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), Ratio: 0.5},
+			layout:   "2006-01-02 15:04:05",
+			want:     "2024-01-02 15:04:05 x0.5",
+		},
+		{
+			name:     "time in other location is formatted in UTC",
+			fakeTime: FakeTime{Time: time.Date(2024, 1, 3, 0, 4, 5, 0, time.FixedLocation)},
+			layout:   "2006-01-02 15:04:05",
+			want:     "2024-01-02 15:04:05",
+		},
+		{
+			name:     "RFC3339",
+			fakeTime: FakeTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), Ratio: 60},
+			layout:   time.RFC3339,
+			want:     "2024-01-02T15:04:05Z x60",
+		},
+		{
+			name:     "paused",
+			fakeTime: FakeTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), PausedRatio: 60},
+			layout:   "2006-01-02 15:04:05",
+			want:     "2024-01-02 15:04:05 x60 paused",
+		},
+		{
+			name:     "increment",
+			fakeTime: FakeTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), Increment: time.Millisecond},
+			layout:   "2006-01-02 15:04:05",
+			want:     "2024-01-02 15:04:05 i1ms",
+		},
+		{
+			name: "anchor",
+			fakeTime: FakeTime{
+				Time:   time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+				Ratio:  2,
+				Anchor: time.Date(2024, 6, 15, 12, 0, 0, 0, time.FixedLocation),
+			},
+			layout: "2006-01-02 15:04:05",
+			want:   "2024-01-02 15:04:05 x2 since=2024-06-15T03:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.fakeTime.Format(tt.layout)
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+
+			parsed, err := Parse(got, tt.layout)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !parsed.Time.Equal(tt.fakeTime.Time) || parsed.Ratio != tt.fakeTime.Ratio || parsed.PausedRatio != tt.fakeTime.PausedRatio || parsed.Increment != tt.fakeTime.Increment || !parsed.Anchor.Equal(tt.fakeTime.Anchor) {
+				t.Errorf("Parse(Format()) = %+v, want %+v", parsed, tt.fakeTime)
+			}
+		})
+	}
+}
+
+func TestFakeTime_Run_Context(t *testing.T) {
+	ft := &FakeTime{
+		Time:  time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+		Ratio: 2,
+		Spec:  "2024-01-02 15:04:05 x2",
+	}
+
+	if _, ok := FromContext(context.Background()); ok {
+		t.Fatal("FromContext() should return false for a context without fake time")
+	}
+
+	before := time.StdNow()
+	err := ft.Run(context.Background(), func(ctx context.Context) error {
+		got, ok := FromContext(ctx)
+		if !ok {
+			t.Fatal("FromContext() should return the fake time")
+		}
+		if !got.Time.Equal(ft.Time) || got.Ratio != ft.Ratio || got.Spec != ft.Spec {
+			t.Errorf("FromContext() = %+v, want %+v", got, ft)
+		}
+		if got.Anchor.Before(before) || got.Anchor.After(time.StdNow()) {
+			t.Errorf("Anchor = %v, want between %v and now", got.Anchor, before)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ft.Anchor.IsZero() {
+		t.Errorf("Run() should not modify the receiver, Anchor = %v", ft.Anchor)
+	}
+}
+
+func TestFakeTime_Setup_Anchor(t *testing.T) {
+	ft := FakeTime{
+		Time:   time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+		Ratio:  2,
+		Anchor: time.StdNow().Add(-time.Hour),
+	}
+
+	cleanup := ft.Setup(context.Background())
+	defer cleanup()
+
+	// Two hours of fake time have passed since the anchor an hour ago.
+	expected := ft.Time.Add(2 * time.Hour)
+	diff := time.Now().Sub(expected)
+	if diff < 0 || diff > time.Second {
+		t.Errorf("time.Now() = %v, want %v (+1s)", time.Now(), expected)
+	}
+}
+
+func TestAnchorSpec(t *testing.T) {
+	layout := "2006-01-02 15:04:05"
+	tests := []struct {
+		name     string
+		spec     string
+		anchored bool
+	}{
+		{name: "frozen", spec: "2024-01-02 15:04:05"},
+		{name: "paused", spec: "2024-01-02 15:04:05 x2 paused"},
+		{name: "anchored", spec: "2024-01-02 15:04:05 x2 since=2024-06-15T03:00:00Z"},
+		{name: "flowing", spec: "2024-01-02 15:04:05 x2", anchored: true},
+		{name: "script", spec: "2024-01-02 15:04:05 for=1h; 2024-01-03 00:00:00 +", anchored: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.StdNow()
+			got, err := AnchorSpec(tt.spec, layout)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.anchored {
+				if got != tt.spec {
+					t.Errorf("AnchorSpec() = %q, want %q as is", got, tt.spec)
+				}
+				return
+			}
+			ft, err := Parse(got, layout)
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", got, err)
+			}
+			if ft.Anchor.Before(before) || ft.Anchor.After(time.StdNow()) {
+				t.Errorf("Anchor = %v, want the real time when it's anchored", ft.Anchor)
+			}
+		})
+	}
+
+	if _, err := AnchorSpec("2024-01-02 15:04:05 x0", layout); !errors.Is(err, ErrInvalidFaketimeFileContent) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidFaketimeFileContent)
+	}
+}
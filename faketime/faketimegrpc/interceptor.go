@@ -0,0 +1,200 @@
+package faketimegrpc
+
+import (
+	"context"
+	"log/slog"
+	orig "time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+const (
+	// MetadataKey holds a spec which is parsed with Layout.
+	MetadataKey = "x-fake-time"
+	// MetadataKeyAnchor holds the real time in Layout when the fake time is the one in MetadataKey.
+	MetadataKeyAnchor = "x-fake-time-anchor"
+
+	Layout = time.RFC3339Nano
+)
+
+// MetadataProvider reads a spec from the incoming metadata in the context.
+type MetadataProvider struct {
+	key string
+}
+
+var _ faketime.SourceProvider = (*MetadataProvider)(nil)
+
+func NewMetadataProvider() *MetadataProvider {
+	return &MetadataProvider{key: MetadataKey}
+}
+
+func (p *MetadataProvider) Get(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", nil
+	}
+	values := md.Get(p.key)
+	if len(values) == 0 {
+		return "", nil
+	}
+	return values[0], nil
+}
+
+func (p *MetadataProvider) Source() time.ClockSource {
+	return time.ClockSourceHeader
+}
+
+// resolve returns nil without error when fake time is not requested.
+func resolve(ctx context.Context, provider faketime.Provider, layout string) (*faketime.FakeTime, error) {
+	s, err := provider.Get(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get faketime", "error", err)
+		return nil, status.Error(codes.Internal, "faketime error")
+	}
+	if s == "" {
+		return nil, nil
+	}
+
+	ft, err := faketime.Parse(s, layout)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to parse faketime", "error", err, "content", s)
+		return nil, status.Error(codes.InvalidArgument, "faketime error")
+	}
+	if p, ok := provider.(faketime.SourceProvider); ok {
+		ft.Source = p.Source()
+	}
+
+	// Clients can't shift the timeline of a spec which they don't give.
+	if _, ok := provider.(*MetadataProvider); !ok {
+		if p, ok := provider.(faketime.AnchorProvider); ok && ft.Anchor.IsZero() {
+			anchor, err := p.Anchor(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to get faketime anchor", "error", err)
+				return nil, status.Error(codes.Internal, "faketime error")
+			}
+			ft.Anchor = anchor
+		}
+		return ft, nil
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(MetadataKeyAnchor); len(values) > 0 {
+			anchor, err := orig.Parse(Layout, values[0])
+			if err != nil {
+				slog.WarnContext(ctx, "failed to parse faketime anchor", "error", err, "content", values[0])
+				return nil, status.Error(codes.InvalidArgument, "faketime error")
+			}
+			ft.Anchor = anchor
+		}
+	}
+	return ft, nil
+}
+
+// logIgnored logs loudly if provider gives a spec while faketime is not enabled,
+// as Middleware of faketimehttp does for its file.
+func logIgnored(provider faketime.Provider) {
+	if s, _ := provider.Get(context.Background()); s != "" {
+		slog.Error("faketime spec is IGNORED because faketime is not enabled", "content", s, "env", faketime.EnvEnabled)
+	}
+}
+
+// UnaryServerInterceptor applies the fake time which provider returns to each call.
+// Use NewMetadataProvider with Layout to read the metadata which the client interceptors send.
+// Unless faketime.Enabled returns true, it calls handlers without fake time
+// and logs an error if provider already gives a spec.
+//
+// time.Now is overridden for the whole process while a call is handled, so concurrent calls
+// with different fake times read the one of the call which started last.
+// Handlers which need the fake time of their own call read it with faketime.ClockFromContext,
+// and the client interceptors send the one in the context.
+func UnaryServerInterceptor(provider faketime.Provider, layout string) grpc.UnaryServerInterceptor {
+	if !faketime.Enabled() {
+		logIgnored(provider)
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(ctx, req)
+		}
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ft, err := resolve(ctx, provider, layout)
+		if err != nil {
+			return nil, err
+		}
+		if ft == nil {
+			return handler(ctx, req)
+		}
+
+		var resp any
+		err = ft.Run(ctx, func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerInterceptor applies the fake time which provider returns to each stream
+// in the same way as UnaryServerInterceptor, including time.Now shared by concurrent streams.
+func StreamServerInterceptor(provider faketime.Provider, layout string) grpc.StreamServerInterceptor {
+	if !faketime.Enabled() {
+		logIgnored(provider)
+		return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, ss)
+		}
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ft, err := resolve(ss.Context(), provider, layout)
+		if err != nil {
+			return err
+		}
+		if ft == nil {
+			return handler(srv, ss)
+		}
+
+		return ft.Run(ss.Context(), func(ctx context.Context) error {
+			return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	ft, ok := faketime.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	// The anchor is sent in its own key rather than in the spec.
+	spec := *ft
+	spec.Anchor = time.Time{}
+	kv := []string{MetadataKey, spec.Format(Layout)}
+	if !ft.Anchor.IsZero() {
+		kv = append(kv, MetadataKeyAnchor, ft.Anchor.UTC().Format(Layout))
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// UnaryClientInterceptor sends the fake time in the context as outgoing metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
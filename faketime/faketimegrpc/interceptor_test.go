@@ -0,0 +1,360 @@
+package faketimegrpc
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+// clockServiceDesc describes a service returning time.Now() on the server
+// without generated code.
+var clockServiceDesc = grpc.ServiceDesc{
+	ServiceName: "faketimegrpc.test.Clock",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Now",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req any) (any, error) {
+					if _, ok := faketime.FromContext(ctx); !ok {
+						return wrapperspb.String("real:" + time.Now().Format(time.RFC3339Nano)), nil
+					}
+					return wrapperspb.String(time.Now().Format(time.RFC3339Nano)), nil
+				}
+				if interceptor == nil {
+					return handler(ctx, in)
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/faketimegrpc.test.Clock/Now"}, handler)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			ServerStreams: true,
+			Handler: func(srv any, stream grpc.ServerStream) error {
+				if err := stream.RecvMsg(new(emptypb.Empty)); err != nil {
+					return err
+				}
+				if _, ok := faketime.FromContext(stream.Context()); !ok {
+					return status.Error(codes.FailedPrecondition, "no fake time in context")
+				}
+				return stream.SendMsg(wrapperspb.String(time.Now().Format(time.RFC3339Nano)))
+			},
+		},
+	},
+}
+
+func startServer(t *testing.T, provider faketime.Provider, layout string) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(provider, layout)),
+		grpc.StreamInterceptor(StreamServerInterceptor(provider, layout)),
+	)
+	server.RegisterService(&clockServiceDesc, struct{}{})
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func callNow(ctx context.Context, conn *grpc.ClientConn) (string, error) {
+	out := new(wrapperspb.StringValue)
+	if err := conn.Invoke(ctx, "/faketimegrpc.test.Clock/Now", new(emptypb.Empty), out); err != nil {
+		return "", err
+	}
+	return out.GetValue(), nil
+}
+
+func callWatch(ctx context.Context, conn *grpc.ClientConn) (string, error) {
+	stream, err := conn.NewStream(ctx, &clockServiceDesc.Streams[0], "/faketimegrpc.test.Clock/Watch")
+	if err != nil {
+		return "", err
+	}
+	if err := stream.SendMsg(new(emptypb.Empty)); err != nil {
+		return "", err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return "", err
+	}
+	out := new(wrapperspb.StringValue)
+	if err := stream.RecvMsg(out); err != nil {
+		return "", err
+	}
+	return out.GetValue(), nil
+}
+
+func TestInterceptors_Metadata(t *testing.T) {
+	conn := startServer(t, NewMetadataProvider(), Layout)
+
+	fakeNow := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)
+	ctx := faketime.NewContext(context.Background(), &faketime.FakeTime{Time: fakeNow})
+
+	for name, call := range map[string]func(context.Context, *grpc.ClientConn) (string, error){
+		"unary":  callNow,
+		"stream": callWatch,
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := call(ctx, conn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			gotTime, err := time.Parse(time.RFC3339Nano, got)
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", got, err)
+			}
+			if !gotTime.Equal(fakeNow) {
+				t.Errorf("time.Now() on server = %v, want %v", gotTime, fakeNow)
+			}
+		})
+	}
+
+	t.Run("anchor is propagated", func(t *testing.T) {
+		ctx := faketime.NewContext(context.Background(), &faketime.FakeTime{
+			Time:   fakeNow,
+			Ratio:  2,
+			Anchor: time.StdNow().Add(-time.Hour),
+		})
+		got, err := callNow(ctx, conn)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		gotTime, err := time.Parse(time.RFC3339Nano, got)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", got, err)
+		}
+		want := fakeNow.Add(2 * time.Hour)
+		if diff := gotTime.Sub(want); diff < 0 || diff > time.Second {
+			t.Errorf("time.Now() on server = %v, want %v (+1s)", gotTime, want)
+		}
+	})
+
+	t.Run("without fake time", func(t *testing.T) {
+		got, err := callNow(context.Background(), conn)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(got, "real:") {
+			t.Errorf("response = %q, want real time", got)
+		}
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), MetadataKey, "2023-06-15 10:30:00")
+		_, err := callNow(ctx, conn)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("error = %v, want code %v", err, codes.InvalidArgument)
+		}
+
+		ctx = metadata.AppendToOutgoingContext(context.Background(), MetadataKey, "2023-06-15T10:30:00Z", MetadataKeyAnchor, "yesterday")
+		_, err = callNow(ctx, conn)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("error = %v, want code %v", err, codes.InvalidArgument)
+		}
+	})
+}
+
+func TestInterceptors_FileProvider(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "time.txt")
+	if err := os.WriteFile(filePath, []byte("2023-06-15 10:30:00"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conn := startServer(t, faketime.NewFileProvider(filePath), time.DateTime)
+
+	got, err := callNow(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gotTime, err := time.Parse(time.RFC3339Nano, got)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", got, err)
+	}
+	if want := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC); !gotTime.Equal(want) {
+		t.Errorf("time.Now() on server = %v, want %v", gotTime, want)
+	}
+}
+
+func TestInterceptors_FileProvider_Anchor(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "time.txt")
+	if err := os.WriteFile(filePath, []byte("2023-06-15 10:30:00 x2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The spec was written an hour ago, so every call follows it from then.
+	modTime := time.StdNow().Add(-time.Hour)
+	if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	conn := startServer(t, faketime.NewFileProvider(filePath), time.DateTime)
+
+	want := time.Date(2023, 6, 15, 12, 30, 0, 0, time.UTC)
+	for range 2 {
+		got, err := callNow(context.Background(), conn)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		gotTime, err := time.Parse(time.RFC3339Nano, got)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", got, err)
+		}
+		if gotTime.Before(want) || gotTime.After(want.Add(time.Minute)) {
+			t.Errorf("time.Now() on server = %v, want just after %v", gotTime, want)
+		}
+	}
+}
+
+func TestInterceptors_FileProvider_IgnoresAnchor(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "time.txt")
+	spec := "2023-06-15 10:30:00 x60 since=" + time.StdNow().UTC().Format(time.RFC3339Nano)
+	if err := os.WriteFile(filePath, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conn := startServer(t, faketime.NewFileProvider(filePath), time.DateTime)
+
+	// An anchor an hour ago would move the fake time 60 hours ahead.
+	anchor := time.StdNow().Add(-time.Hour).UTC().Format(Layout)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), MetadataKeyAnchor, anchor)
+	got, err := callNow(ctx, conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gotTime, err := time.Parse(time.RFC3339Nano, got)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", got, err)
+	}
+	if want := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC); gotTime.Before(want) || gotTime.After(want.Add(time.Hour)) {
+		t.Errorf("time.Now() on server = %v, want %v (+1h)", gotTime, want)
+	}
+}
+
+func TestInterceptors_NotEnabled(t *testing.T) {
+	t.Setenv(faketime.EnvEnabled, "false")
+	if faketime.Enabled() {
+		t.Skip("faketime is enabled by the build tag")
+	}
+
+	conn := startServer(t, NewMetadataProvider(), Layout)
+	ctx := faketime.NewContext(context.Background(), &faketime.FakeTime{Time: time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)})
+
+	got, err := callNow(ctx, conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(got, "real:") {
+		t.Errorf("response = %q, want real time", got)
+	}
+
+	if _, err := callWatch(ctx, conn); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("error = %v, want code %v", err, codes.FailedPrecondition)
+	}
+}
+
+func TestInterceptors_NotEnabled_FileProvider(t *testing.T) {
+	t.Setenv(faketime.EnvEnabled, "false")
+	if faketime.Enabled() {
+		t.Skip("faketime is enabled by the build tag")
+	}
+
+	filePath := filepath.Join(t.TempDir(), "time.txt")
+	if err := os.WriteFile(filePath, []byte("2023-06-15 10:30:00"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	provider := faketime.NewFileProvider(filePath)
+	_ = UnaryServerInterceptor(provider, time.DateTime)
+	_ = StreamServerInterceptor(provider, time.DateTime)
+
+	if got := strings.Count(logs.String(), "level=ERROR"); got != 2 || !strings.Contains(logs.String(), "2023-06-15 10:30:00") {
+		t.Errorf("an error should be logged by each interceptor for the ignored spec, logs: %s", logs.String())
+	}
+
+	logs.Reset()
+	_ = UnaryServerInterceptor(NewMetadataProvider(), Layout)
+	if logs.Len() > 0 {
+		t.Errorf("nothing should be logged without a spec, logs: %s", logs.String())
+	}
+}
+
+func TestUnaryServerInterceptor_Concurrent(t *testing.T) {
+	first := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)
+	second := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	interceptor := UnaryServerInterceptor(NewMetadataProvider(), Layout)
+	info := &grpc.UnaryServerInfo{FullMethod: "/faketimegrpc.test.Clock/Now"}
+	call := func(spec time.Time, handler grpc.UnaryHandler) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, spec.Format(Layout)))
+		if _, err := interceptor(ctx, nil, info, handler); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	firstStarted, secondStarted := make(chan struct{}), make(chan struct{})
+	firstRead, secondDone := make(chan struct{}), make(chan struct{})
+	var firstNow, firstClockNow, firstNowAfter time.Time
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		call(first, func(ctx context.Context, req any) (any, error) {
+			close(firstStarted)
+			<-secondStarted
+			firstNow = time.Now()
+			clock, _ := faketime.ClockFromContext(ctx)
+			firstClockNow = clock.Now()
+			close(firstRead)
+			<-secondDone
+			firstNowAfter = time.Now()
+			return nil, nil
+		})
+	}()
+	<-firstStarted
+	call(second, func(ctx context.Context, req any) (any, error) {
+		close(secondStarted)
+		<-firstRead
+		return nil, nil
+	})
+	close(secondDone)
+	<-done
+
+	if !firstNow.Equal(second) {
+		t.Errorf("time.Now() while both calls run = %v, want %v of the later one", firstNow, second)
+	}
+	if !firstClockNow.Equal(first) {
+		t.Errorf("Clock.Now() = %v, want %v of its own call", firstClockNow, first)
+	}
+	if !firstNowAfter.Equal(first) {
+		t.Errorf("time.Now() after the later call = %v, want %v", firstNowAfter, first)
+	}
+}
@@ -0,0 +1,180 @@
+package faketimehttp
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+type adminConfig struct {
+	username string
+	password string
+	token    string
+}
+
+type AdminOption func(*adminConfig)
+
+func WithBasicAuth(username, password string) AdminOption {
+	return func(c *adminConfig) {
+		c.username = username
+		c.password = password
+	}
+}
+
+func WithBearerToken(token string) AdminOption {
+	return func(c *adminConfig) {
+		c.token = token
+	}
+}
+
+func (c *adminConfig) protected() bool {
+	return c.username != "" || c.token != ""
+}
+
+func (c *adminConfig) authorized(r *http.Request) bool {
+	if c.username != "" {
+		if username, password, ok := r.BasicAuth(); ok &&
+			subtle.ConstantTimeCompare([]byte(username), []byte(c.username)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(password), []byte(c.password)) == 1 {
+			return true
+		}
+	}
+	if c.token != "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+type AdminState struct {
+	Spec        string    `json:"spec"`
+	Active      bool      `json:"active"`
+	Now         time.Time `json:"now"`
+	Ratio       float64   `json:"ratio"`
+	PausedRatio float64   `json:"pausedRatio,omitempty"`
+	Real        time.Time `json:"real"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// AdminRequest holds either Spec or Time with optional Ratio.
+// Time is formatted with the layout of the file so that clients don't have to know it.
+type AdminRequest struct {
+	Spec  string     `json:"spec,omitempty"`
+	Time  *time.Time `json:"time,omitempty"`
+	Ratio float64    `json:"ratio,omitempty"`
+}
+
+type adminError struct {
+	Error string `json:"error"`
+}
+
+// AdminHandler serves the content of file as JSON.
+// GET returns the current state, PUT and POST replace the spec and DELETE removes the file.
+func AdminHandler(file *faketime.File, opts ...AdminOption) http.Handler {
+	cfg := &adminConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	provider := faketime.NewFileProvider(file.FilePath)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if cfg.protected() && !cfg.authorized(r) {
+			if cfg.username != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="faketime"`)
+			}
+			writeJSON(w, http.StatusUnauthorized, &adminError{Error: "unauthorized"})
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			var req AdminRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, &adminError{Error: "invalid request body: " + err.Error()})
+				return
+			}
+			spec := strings.TrimSpace(req.Spec)
+			if req.Time != nil {
+				if spec != "" {
+					writeJSON(w, http.StatusBadRequest, &adminError{Error: "spec and time must not be given together"})
+					return
+				}
+				spec = (&faketime.FakeTime{Time: *req.Time, Ratio: req.Ratio}).Format(file.Layout())
+			}
+			spec, err := faketime.AnchorSpec(spec, file.Layout())
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, &adminError{Error: err.Error()})
+				return
+			}
+			if err := file.SaveSpec(spec); err != nil {
+				slog.ErrorContext(ctx, "failed to save faketime", "error", err, "file", file.FilePath)
+				writeJSON(w, http.StatusInternalServerError, &adminError{Error: "failed to save faketime"})
+				return
+			}
+		case http.MethodDelete:
+			if err := file.Delete(); err != nil {
+				slog.ErrorContext(ctx, "failed to delete faketime", "error", err, "file", file.FilePath)
+				writeJSON(w, http.StatusInternalServerError, &adminError{Error: "failed to delete faketime"})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, POST, DELETE")
+			writeJSON(w, http.StatusMethodNotAllowed, &adminError{Error: "method not allowed"})
+			return
+		}
+
+		s, err := provider.Get(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to get faketime", "error", err, "file", file.FilePath)
+			writeJSON(w, http.StatusInternalServerError, &adminError{Error: "failed to get faketime"})
+			return
+		}
+
+		realNow := time.StdNow().In(time.CurrentLocation())
+		state := &AdminState{Spec: s, Now: realNow, Real: realNow}
+		if s != "" {
+			ft, err := faketime.Parse(s, file.Layout())
+			if err != nil {
+				// The file can be edited by hand, so report the broken content instead of failing.
+				state.Error = err.Error()
+				writeJSON(w, http.StatusOK, state)
+				return
+			}
+			if ft.Anchor.IsZero() {
+				// Report the same timeline as Middleware reading the file.
+				if ft.Anchor, err = provider.Anchor(ctx); err != nil {
+					slog.ErrorContext(ctx, "failed to get faketime anchor", "error", err, "file", file.FilePath)
+					writeJSON(w, http.StatusInternalServerError, &adminError{Error: "failed to get faketime"})
+					return
+				}
+			}
+			state.Active = true
+			clock := faketime.NewClock(ft)
+			state.Now = clock.Now()
+			// The ratio of the current step of a script, which is 0 while it's paused.
+			state.Ratio = clock.Ratio()
+			state.PausedRatio = clock.PausedRatio()
+		}
+		writeJSON(w, http.StatusOK, state)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to write JSON response", "error", err)
+	}
+}
@@ -0,0 +1,361 @@
+package faketimehttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+// scrubAnchor replaces the anchor, which is the real time when a spec is saved.
+func scrubAnchor(spec string) string {
+	return anchorPattern.ReplaceAllString(spec, "since=<anchor>")
+}
+
+var anchorPattern = regexp.MustCompile(`since=\S+`)
+
+func TestAdminHandler(t *testing.T) {
+	newFile := func(t *testing.T, content string) *faketime.File {
+		filePath := filepath.Join(t.TempDir(), "time.txt")
+		if content != "" {
+			if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return faketime.NewFile(filePath, time.DateTime)
+	}
+
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) *AdminState {
+		var state AdminState
+		if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return &state
+	}
+
+	t.Run("GET", func(t *testing.T) {
+		anchored := "2024-01-02 15:04:05 x60 since=" + time.StdNow().Add(-time.Minute).UTC().Format(time.RFC3339Nano)
+		script := "2024-01-02 15:04:05 since=" + time.StdNow().Add(-time.Hour).UTC().Format(time.RFC3339Nano) + " for=1m; 2024-06-01 00:00:00 x2"
+		written := "2024-01-02 15:04:05 x60"
+		tests := []struct {
+			name            string
+			content         string
+			modTime         time.Time
+			wantSpec        string
+			wantActive      bool
+			wantNow         time.Time
+			wantRatio       float64
+			wantPausedRatio float64
+			wantErr         bool
+		}{
+			{
+				name: "file does not exist",
+			},
+			{
+				name:       "frozen time",
+				content:    "2024-01-02 15:04:05",
+				wantSpec:   "2024-01-02 15:04:05",
+				wantActive: true,
+				wantNow:    time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+			},
+			{
+				name:       "time with ratio",
+				content:    "2024-01-02 15:04:05 x2\n",
+				wantSpec:   "2024-01-02 15:04:05 x2",
+				wantActive: true,
+				wantNow:    time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+				wantRatio:  2,
+			},
+			{
+				name:       "anchored time with ratio",
+				content:    anchored,
+				wantSpec:   anchored,
+				wantActive: true,
+				wantNow:    time.Date(2024, 1, 2, 16, 4, 5, 0, time.UTC),
+				wantRatio:  60,
+			},
+			{
+				name:       "script at its second step",
+				content:    script,
+				wantSpec:   script,
+				wantActive: true,
+				wantNow:    time.Date(2024, 6, 1, 1, 58, 0, 0, time.UTC),
+				wantRatio:  2,
+			},
+			{
+				name:       "time with ratio written a minute ago",
+				content:    written,
+				modTime:    time.StdNow().Add(-time.Minute),
+				wantSpec:   written,
+				wantActive: true,
+				wantNow:    time.Date(2024, 1, 2, 16, 4, 5, 0, time.UTC),
+				wantRatio:  60,
+			},
+			{
+				name:            "paused time",
+				content:         "2024-01-02 15:04:05 x60 paused",
+				wantSpec:        "2024-01-02 15:04:05 x60 paused",
+				wantActive:      true,
+				wantNow:         time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+				wantPausedRatio: 60,
+			},
+			{
+				name:     "broken content",
+				content:  "not-a-time",
+				wantSpec: "not-a-time",
+				wantErr:  true,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				file := newFile(t, tt.content)
+				if !tt.modTime.IsZero() {
+					if err := os.Chtimes(file.FilePath, tt.modTime, tt.modTime); err != nil {
+						t.Fatal(err)
+					}
+				}
+				handler := AdminHandler(file)
+
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				if rec.Code != http.StatusOK {
+					t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+				}
+				if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+					t.Errorf("Content-Type = %q, want application/json", ct)
+				}
+
+				state := decode(t, rec)
+				if state.Spec != tt.wantSpec {
+					t.Errorf("spec = %q, want %q", state.Spec, tt.wantSpec)
+				}
+				if state.Active != tt.wantActive {
+					t.Errorf("active = %v, want %v", state.Active, tt.wantActive)
+				}
+				if state.Ratio != tt.wantRatio {
+					t.Errorf("ratio = %v, want %v", state.Ratio, tt.wantRatio)
+				}
+				if state.PausedRatio != tt.wantPausedRatio {
+					t.Errorf("pausedRatio = %v, want %v", state.PausedRatio, tt.wantPausedRatio)
+				}
+				if (state.Error != "") != tt.wantErr {
+					t.Errorf("error = %q, want error: %v", state.Error, tt.wantErr)
+				}
+				// The fake time flows while the request is served.
+				if tt.wantActive && (state.Now.Before(tt.wantNow) || state.Now.After(tt.wantNow.Add(10*time.Second))) {
+					t.Errorf("now = %v, want %v (+10s)", state.Now, tt.wantNow)
+				}
+			})
+		}
+	})
+
+	t.Run("PUT and POST", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			method      string
+			body        string
+			wantCode    int
+			wantContent string
+		}{
+			{
+				name:        "PUT valid spec",
+				method:      http.MethodPut,
+				body:        `{"spec": "2024-01-02 15:04:05 x2"}`,
+				wantCode:    http.StatusOK,
+				wantContent: "2024-01-02 15:04:05 x2 since=<anchor>",
+			},
+			{
+				name:        "POST valid spec",
+				method:      http.MethodPost,
+				body:        `{"spec": " +1h "}`,
+				wantCode:    http.StatusOK,
+				wantContent: "+1h",
+			},
+			{
+				name:        "time with ratio",
+				method:      http.MethodPut,
+				body:        `{"time": "2024-01-02T15:04:05Z", "ratio": 2}`,
+				wantCode:    http.StatusOK,
+				wantContent: "2024-01-02 15:04:05 x2 since=<anchor>",
+			},
+			{
+				name:     "spec and time together",
+				method:   http.MethodPut,
+				body:     `{"spec": "+1h", "time": "2024-01-02T15:04:05Z"}`,
+				wantCode: http.StatusBadRequest,
+			},
+			{
+				name:     "time with negative ratio",
+				method:   http.MethodPut,
+				body:     `{"time": "2024-01-02T15:04:05Z", "ratio": -1}`,
+				wantCode: http.StatusBadRequest,
+			},
+			{
+				name:     "invalid spec",
+				method:   http.MethodPut,
+				body:     `{"spec": "2024-01-02T15:04:05Z"}`,
+				wantCode: http.StatusBadRequest,
+			},
+			{
+				name:     "empty spec",
+				method:   http.MethodPut,
+				body:     `{"spec": ""}`,
+				wantCode: http.StatusBadRequest,
+			},
+			{
+				name:     "invalid JSON",
+				method:   http.MethodPost,
+				body:     `2024-01-02 15:04:05`,
+				wantCode: http.StatusBadRequest,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				file := newFile(t, "")
+				handler := AdminHandler(file)
+
+				req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				if rec.Code != tt.wantCode {
+					t.Fatalf("got status %d, want %d, body: %s", rec.Code, tt.wantCode, rec.Body.String())
+				}
+
+				content, err := os.ReadFile(file.FilePath)
+				if tt.wantContent == "" {
+					if !os.IsNotExist(err) {
+						t.Errorf("file should not be written, content: %q, error: %v", string(content), err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("failed to read file: %v", err)
+				}
+				if got := scrubAnchor(string(content)); got != tt.wantContent {
+					t.Errorf("file content = %q, want %q", got, tt.wantContent)
+				}
+				if state := decode(t, rec); !state.Active || scrubAnchor(state.Spec) != tt.wantContent {
+					t.Errorf("state = %+v, want active with spec %q", state, tt.wantContent)
+				}
+			})
+		}
+	})
+
+	t.Run("DELETE", func(t *testing.T) {
+		file := newFile(t, "2024-01-02 15:04:05")
+		handler := AdminHandler(file)
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+		}
+		if _, err := os.Stat(file.FilePath); !os.IsNotExist(err) {
+			t.Error("file should not exist after DELETE")
+		}
+		if state := decode(t, rec); state.Active || state.Spec != "" {
+			t.Errorf("state = %+v, want inactive", state)
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		handler := AdminHandler(newFile(t, ""))
+
+		req := httptest.NewRequest(http.MethodPatch, "/", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+		}
+		if rec.Header().Get("Allow") == "" {
+			t.Error("Allow header should be set")
+		}
+	})
+
+	t.Run("authentication", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			opts     []AdminOption
+			setup    func(r *http.Request)
+			wantCode int
+		}{
+			{
+				name:     "no protection",
+				setup:    func(r *http.Request) {},
+				wantCode: http.StatusOK,
+			},
+			{
+				name:     "basic auth without credentials",
+				opts:     []AdminOption{WithBasicAuth("admin", "secret")},
+				setup:    func(r *http.Request) {},
+				wantCode: http.StatusUnauthorized,
+			},
+			{
+				name:     "basic auth with wrong password",
+				opts:     []AdminOption{WithBasicAuth("admin", "secret")},
+				setup:    func(r *http.Request) { r.SetBasicAuth("admin", "wrong") },
+				wantCode: http.StatusUnauthorized,
+			},
+			{
+				name:     "basic auth with valid credentials",
+				opts:     []AdminOption{WithBasicAuth("admin", "secret")},
+				setup:    func(r *http.Request) { r.SetBasicAuth("admin", "secret") },
+				wantCode: http.StatusOK,
+			},
+			{
+				name:     "token without header",
+				opts:     []AdminOption{WithBearerToken("t0ken")},
+				setup:    func(r *http.Request) {},
+				wantCode: http.StatusUnauthorized,
+			},
+			{
+				name:     "token with wrong value",
+				opts:     []AdminOption{WithBearerToken("t0ken")},
+				setup:    func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") },
+				wantCode: http.StatusUnauthorized,
+			},
+			{
+				name:     "token with valid value",
+				opts:     []AdminOption{WithBearerToken("t0ken")},
+				setup:    func(r *http.Request) { r.Header.Set("Authorization", "Bearer t0ken") },
+				wantCode: http.StatusOK,
+			},
+			{
+				name:     "token accepted when both are configured",
+				opts:     []AdminOption{WithBasicAuth("admin", "secret"), WithBearerToken("t0ken")},
+				setup:    func(r *http.Request) { r.Header.Set("Authorization", "Bearer t0ken") },
+				wantCode: http.StatusOK,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				handler := AdminHandler(newFile(t, ""), tt.opts...)
+
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				tt.setup(req)
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				if rec.Code != tt.wantCode {
+					t.Errorf("got status %d, want %d", rec.Code, tt.wantCode)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package faketimehttp
+
+import (
+	"log/slog"
+	"net/http"
+	orig "time"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+const (
+	// HeaderFakeTime holds a spec which is parsed with HeaderLayout.
+	HeaderFakeTime = "X-Fake-Time"
+	// HeaderFakeTimeAnchor holds the real time in HeaderLayout when the fake time is the one in HeaderFakeTime.
+	HeaderFakeTimeAnchor = "X-Fake-Time-Anchor"
+
+	// CookieName is the cookie which holds a spec for browsers. It's used when HeaderFakeTime is not given.
+	CookieName = "faketime"
+
+	HeaderLayout = time.RFC3339Nano
+)
+
+// WithSecret makes HeaderMiddleware accept only values signed by SignToken with secret.
+// It panics with ErrEmptySecret if secret is empty, such as an unset environment variable,
+// because anyone could sign values with it.
+func WithSecret(secret []byte) MiddlewareOption {
+	if len(secret) == 0 {
+		panic(ErrEmptySecret)
+	}
+	return func(c *middlewareConfig) {
+		c.secret = secret
+	}
+}
+
+// HeaderMiddleware applies the fake time given by the request headers which Transport sends,
+// or by the cookie named CookieName.
+// Unless faketime.Enabled returns true, it passes requests through and ignores them.
+//
+// time.Now is overridden for the whole process while a request is handled, so concurrent requests
+// with different fake times read the one of the request which started last.
+// Handlers which need the fake time of their own request read it with faketime.ClockFromContext.
+func HeaderMiddleware(opts ...MiddlewareOption) func(next http.Handler) http.Handler {
+	if !faketime.Enabled() {
+		return passThrough
+	}
+
+	cfg := newMiddlewareConfig(opts)
+
+	// verify returns v as is unless WithSecret is given.
+	verify := func(r *http.Request, name, v string) (string, error) {
+		if cfg.secret == nil {
+			return v, nil
+		}
+		signedFor := name
+		if name == CookieName {
+			// The cookie holds a spec as HeaderFakeTime does.
+			signedFor = HeaderFakeTime
+		}
+		// Expiry must be checked with the real clock which clients can't change.
+		value, err := VerifyToken(cfg.secret, signedFor, v, time.StdNow())
+		if err != nil {
+			slog.WarnContext(r.Context(), "failed to verify faketime token", "error", err, "name", name)
+			return "", err
+		}
+		return value, nil
+	}
+
+	return newMiddleware("header", http.StatusBadRequest, cfg, func(r *http.Request) (*faketime.FakeTime, error) {
+		ctx := r.Context()
+
+		name := HeaderFakeTime
+		s := r.Header.Get(HeaderFakeTime)
+		if s == "" {
+			if c, err := r.Cookie(CookieName); err == nil {
+				name, s = CookieName, c.Value
+			}
+		}
+		if s == "" {
+			return nil, nil
+		}
+
+		s, err := verify(r, name, s)
+		if err != nil {
+			return nil, err
+		}
+
+		ft, err := faketime.Parse(s, HeaderLayout)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to parse faketime", "error", err, "name", name, "content", s)
+			return nil, err
+		}
+
+		if v := r.Header.Get(HeaderFakeTimeAnchor); v != "" {
+			v, err := verify(r, HeaderFakeTimeAnchor, v)
+			if err != nil {
+				return nil, err
+			}
+			anchor, err := orig.Parse(HeaderLayout, v)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to parse faketime anchor", "error", err, "header", HeaderFakeTimeAnchor, "content", v)
+				return nil, err
+			}
+			ft.Anchor = anchor
+		}
+		ft.Source = time.ClockSourceHeader
+		return ft, nil
+	})
+}
+
+// Transport forwards the fake time in the request context to another service
+// which uses HeaderMiddleware, so that both of them share the same timeline.
+// It reads the fake time from the context rather than time.Now,
+// so it forwards the one of its own request even while other requests override time.Now.
+type Transport struct {
+	// Base is used to send requests. If it's nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+	// Secret signs the headers for HeaderMiddleware with WithSecret. They are sent unsigned if it's nil,
+	// and RoundTrip returns ErrEmptySecret if it's empty but not nil.
+	Secret []byte
+	// TTL is how long signed headers are valid. If it's zero, DefaultTokenTTL is used.
+	TTL time.Duration
+}
+
+const DefaultTokenTTL = time.Minute
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ft, ok := faketime.FromContext(req.Context())
+	if !ok {
+		return base.RoundTrip(req)
+	}
+
+	sign := func(name, v string) string { return v }
+	if t.Secret != nil {
+		if len(t.Secret) == 0 {
+			return nil, ErrEmptySecret
+		}
+		ttl := t.TTL
+		if ttl == 0 {
+			ttl = DefaultTokenTTL
+		}
+		expiresAt := time.StdNow().Add(ttl)
+		sign = func(name, v string) string { return SignToken(t.Secret, name, v, expiresAt) }
+	}
+
+	// RoundTrip must not modify the given request.
+	req = req.Clone(req.Context())
+	// The anchor is sent in its own header rather than in the spec.
+	spec := *ft
+	spec.Anchor = time.Time{}
+	req.Header.Set(HeaderFakeTime, sign(HeaderFakeTime, spec.Format(HeaderLayout)))
+	if !ft.Anchor.IsZero() {
+		req.Header.Set(HeaderFakeTimeAnchor, sign(HeaderFakeTimeAnchor, ft.Anchor.UTC().Format(HeaderLayout)))
+	}
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,514 @@
+package faketimehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+func TestHeaderMiddleware(t *testing.T) {
+	realNow := time.StdNow()
+
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		wantCode      int
+		wantFake      bool
+		wantTime      time.Time
+		wantTolerance time.Duration
+	}{
+		{
+			name:     "no header",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "RFC3339",
+			headers:  map[string]string{HeaderFakeTime: "2023-06-15T10:30:00Z"},
+			wantCode: http.StatusOK,
+			wantFake: true,
+			wantTime: time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "RFC3339Nano with ratio",
+			headers:  map[string]string{HeaderFakeTime: "2023-06-15T10:30:00.123456789+09:00 x2"},
+			wantCode: http.StatusOK,
+			wantFake: true,
+			wantTime: time.Date(2023, 6, 15, 1, 30, 0, 123456789, time.UTC),
+			// the timeline starts when the request arrives
+			wantTolerance: time.Second,
+		},
+		{
+			name: "anchor in the past",
+			headers: map[string]string{
+				HeaderFakeTime:       "2023-06-15T10:30:00Z x2",
+				HeaderFakeTimeAnchor: realNow.Add(-time.Hour).UTC().Format(HeaderLayout),
+			},
+			wantCode:      http.StatusOK,
+			wantFake:      true,
+			wantTime:      time.Date(2023, 6, 15, 12, 30, 0, 0, time.UTC),
+			wantTolerance: time.Second,
+		},
+		{
+			name:     "invalid spec",
+			headers:  map[string]string{HeaderFakeTime: "2023-06-15 10:30:00"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid anchor",
+			headers: map[string]string{
+				HeaderFakeTime:       "2023-06-15T10:30:00Z",
+				HeaderFakeTimeAnchor: "yesterday",
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedTime time.Time
+			var capturedFake bool
+			handler := HeaderMiddleware()(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					capturedTime = time.Now()
+					_, capturedFake = faketime.FromContext(r.Context())
+					w.WriteHeader(http.StatusOK)
+				}),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.wantCode)
+			}
+			if capturedFake != tt.wantFake {
+				t.Errorf("fake time in context = %v, want %v", capturedFake, tt.wantFake)
+			}
+			if !tt.wantFake {
+				return
+			}
+
+			diff := capturedTime.Sub(tt.wantTime)
+			if diff < 0 || diff > tt.wantTolerance {
+				t.Errorf("time: got %v, want %v (+%v)", capturedTime.UTC(), tt.wantTime, tt.wantTolerance)
+			}
+		})
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	var captured *http.Request
+	transport := &Transport{Base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		captured = req
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})}
+	client := &http.Client{Transport: transport}
+
+	t.Run("without fake time", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+
+		for _, key := range []string{HeaderFakeTime, HeaderFakeTimeAnchor} {
+			if got := captured.Header.Get(key); got != "" {
+				t.Errorf("%s = %q, want empty", key, got)
+			}
+		}
+	})
+
+	t.Run("with fake time", func(t *testing.T) {
+		anchor := time.Date(2026, 10, 18, 1, 2, 3, 456, time.UTC)
+		ft := &faketime.FakeTime{
+			Time:   time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC),
+			Ratio:  60,
+			Anchor: anchor,
+		}
+		req, err := http.NewRequestWithContext(faketime.NewContext(context.Background(), ft), http.MethodGet, "http://example.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+
+		if got, want := captured.Header.Get(HeaderFakeTime), "2023-06-15T10:30:00Z x60"; got != want {
+			t.Errorf("%s = %q, want %q", HeaderFakeTime, got, want)
+		}
+		if got, want := captured.Header.Get(HeaderFakeTimeAnchor), "2026-10-18T01:02:03.000000456Z"; got != want {
+			t.Errorf("%s = %q, want %q", HeaderFakeTimeAnchor, got, want)
+		}
+		if req.Header.Get(HeaderFakeTime) != "" {
+			t.Error("Transport should not modify the original request")
+		}
+	})
+}
+
+func TestTransport_Propagation(t *testing.T) {
+	downstream := httptest.NewServer(HeaderMiddleware()(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(time.Now().Format(time.RFC3339Nano)))
+		}),
+	))
+	defer downstream.Close()
+
+	filePath := filepath.Join(t.TempDir(), "time.txt")
+	if err := os.WriteFile(filePath, []byte("2023-06-15 10:30:00"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: &Transport{}}
+	var upstreamTime, downstreamTime time.Time
+	handler := Middleware(filePath, time.DateTime)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upstreamTime = time.Now()
+
+			req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, downstream.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			var body [64]byte
+			n, _ := resp.Body.Read(body[:])
+			downstreamTime, err = time.Parse(time.RFC3339Nano, string(body[:n]))
+			if err != nil {
+				t.Fatalf("failed to parse downstream time %q: %v", string(body[:n]), err)
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !downstreamTime.Equal(upstreamTime) {
+		t.Errorf("downstream time = %v, want %v", downstreamTime, upstreamTime)
+	}
+}
+
+func TestHeaderMiddleware_Concurrent(t *testing.T) {
+	first := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)
+	second := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	firstStarted, secondStarted := make(chan struct{}), make(chan struct{})
+	firstRead, secondDone := make(chan struct{}), make(chan struct{})
+	var firstNow, firstClockNow, firstNowAfter time.Time
+	var forwarded string
+	transport := &Transport{Base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		forwarded = req.Header.Get(HeaderFakeTime)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})}
+	handler := HeaderMiddleware()(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(HeaderFakeTime) == second.Format(time.RFC3339) {
+				close(secondStarted)
+				<-firstRead
+				return
+			}
+			close(firstStarted)
+			<-secondStarted
+			firstNow = time.Now()
+			clock, _ := faketime.ClockFromContext(r.Context())
+			firstClockNow = clock.Now()
+			req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, "http://example.com/", nil)
+			if resp, err := transport.RoundTrip(req); err == nil {
+				_ = resp.Body.Close()
+			}
+			close(firstRead)
+			<-secondDone
+			firstNowAfter = time.Now()
+		}),
+	)
+	serve := func(spec time.Time) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderFakeTime, spec.Format(time.RFC3339))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serve(first)
+	}()
+	<-firstStarted
+	serve(second)
+	close(secondDone)
+	<-done
+
+	if !firstNow.Equal(second) {
+		t.Errorf("time.Now() while both requests run = %v, want %v of the later one", firstNow, second)
+	}
+	if !firstClockNow.Equal(first) {
+		t.Errorf("Clock.Now() = %v, want %v of its own request", firstClockNow, first)
+	}
+	if want := first.Format(time.RFC3339); forwarded != want {
+		t.Errorf("Transport forwarded %q, want %q of its own request", forwarded, want)
+	}
+	if !firstNowAfter.Equal(first) {
+		t.Errorf("time.Now() after the later request = %v, want %v", firstNowAfter, first)
+	}
+}
+
+func TestHeaderMiddleware_NotEnabled(t *testing.T) {
+	t.Setenv(faketime.EnvEnabled, "false")
+	if faketime.Enabled() {
+		t.Skip("faketime is enabled by the build tag")
+	}
+
+	var fake bool
+	handler := HeaderMiddleware()(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, fake = faketime.FromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderFakeTime, "2023-06-15T10:30:00Z")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if fake {
+		t.Error("fake time should not be applied")
+	}
+}
+
+func TestHeaderMiddleware_Cookie(t *testing.T) {
+	var capturedTime time.Time
+	handler := HeaderMiddleware()(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			capturedTime = time.Now()
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: CookieName, Value: "2023-06-15T10:30:00Z"})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if want := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC); !capturedTime.Equal(want) {
+		t.Errorf("time: got %v, want %v", capturedTime, want)
+	}
+}
+
+func TestHeaderMiddleware_WithSecret(t *testing.T) {
+	secret := []byte("s3cret")
+	fakeNow := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)
+	expiresAt := time.StdNow().Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		setup    func(r *http.Request)
+		wantCode int
+		wantFake bool
+	}{
+		{
+			name: "signed header",
+			setup: func(r *http.Request) {
+				r.Header.Set(HeaderFakeTime, SignToken(secret, HeaderFakeTime, "2023-06-15T10:30:00Z", expiresAt))
+			},
+			wantCode: http.StatusOK,
+			wantFake: true,
+		},
+		{
+			name: "signed header with signed anchor",
+			setup: func(r *http.Request) {
+				r.Header.Set(HeaderFakeTime, SignToken(secret, HeaderFakeTime, "2023-06-15T10:30:00Z", expiresAt))
+				r.Header.Set(HeaderFakeTimeAnchor, SignToken(secret, HeaderFakeTimeAnchor, time.StdNow().UTC().Format(HeaderLayout), expiresAt))
+			},
+			wantCode: http.StatusOK,
+			wantFake: true,
+		},
+		{
+			name: "signed cookie",
+			setup: func(r *http.Request) {
+				r.AddCookie(&http.Cookie{Name: CookieName, Value: SignToken(secret, HeaderFakeTime, "2023-06-15T10:30:00Z", expiresAt)})
+			},
+			wantCode: http.StatusOK,
+			wantFake: true,
+		},
+		{
+			name: "unsigned header",
+			setup: func(r *http.Request) {
+				r.Header.Set(HeaderFakeTime, "2023-06-15T10:30:00Z")
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "unsigned anchor",
+			setup: func(r *http.Request) {
+				r.Header.Set(HeaderFakeTime, SignToken(secret, HeaderFakeTime, "2023-06-15T10:30:00Z", expiresAt))
+				r.Header.Set(HeaderFakeTimeAnchor, time.StdNow().UTC().Format(HeaderLayout))
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "anchor token as spec",
+			setup: func(r *http.Request) {
+				r.Header.Set(HeaderFakeTime, SignToken(secret, HeaderFakeTimeAnchor, "2023-06-15T10:30:00Z", expiresAt))
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "spec token as anchor",
+			setup: func(r *http.Request) {
+				r.Header.Set(HeaderFakeTime, SignToken(secret, HeaderFakeTime, "2023-06-15T10:30:00Z", expiresAt))
+				r.Header.Set(HeaderFakeTimeAnchor, SignToken(secret, HeaderFakeTime, time.StdNow().UTC().Format(HeaderLayout), expiresAt))
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "signed with other secret",
+			setup: func(r *http.Request) {
+				r.Header.Set(HeaderFakeTime, SignToken([]byte("other"), HeaderFakeTime, "2023-06-15T10:30:00Z", expiresAt))
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "expired token",
+			setup: func(r *http.Request) {
+				r.Header.Set(HeaderFakeTime, SignToken(secret, HeaderFakeTime, "2023-06-15T10:30:00Z", time.StdNow().Add(-time.Second)))
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "signed invalid spec",
+			setup: func(r *http.Request) {
+				r.Header.Set(HeaderFakeTime, SignToken(secret, HeaderFakeTime, "2023-06-15 10:30:00", expiresAt))
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedTime time.Time
+			var capturedFake bool
+			handler := HeaderMiddleware(WithSecret(secret))(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					capturedTime = time.Now()
+					_, capturedFake = faketime.FromContext(r.Context())
+					w.WriteHeader(http.StatusOK)
+				}),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			tt.setup(req)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.wantCode)
+			}
+			if capturedFake != tt.wantFake {
+				t.Errorf("fake time in context = %v, want %v", capturedFake, tt.wantFake)
+			}
+			if tt.wantFake && !capturedTime.Equal(fakeNow) {
+				t.Errorf("time: got %v, want %v", capturedTime, fakeNow)
+			}
+		})
+	}
+}
+
+func TestWithSecret_Empty(t *testing.T) {
+	for _, secret := range [][]byte{nil, {}} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrEmptySecret {
+					t.Errorf("WithSecret(%#v) panics with %v, want %v", secret, r, ErrEmptySecret)
+				}
+			}()
+			WithSecret(secret)
+		}()
+	}
+}
+
+func TestTransport_Secret(t *testing.T) {
+	secret := []byte("s3cret")
+	fakeNow := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)
+
+	var capturedTime time.Time
+	downstream := httptest.NewServer(HeaderMiddleware(WithSecret(secret))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			capturedTime = time.Now()
+			w.WriteHeader(http.StatusOK)
+		}),
+	))
+	defer downstream.Close()
+
+	for _, tc := range []struct {
+		name      string
+		transport *Transport
+		wantCode  int
+	}{
+		{name: "signed", transport: &Transport{Secret: secret}, wantCode: http.StatusOK},
+		{name: "unsigned", transport: &Transport{}, wantCode: http.StatusBadRequest},
+		{name: "empty secret", transport: &Transport{Secret: []byte{}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := faketime.NewContext(context.Background(), &faketime.FakeTime{Time: fakeNow, Anchor: time.StdNow()})
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, downstream.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&http.Client{Transport: tc.transport}).Do(req)
+			if tc.wantCode == 0 {
+				if !errors.Is(err, ErrEmptySecret) {
+					t.Fatalf("expected error %v, got %v", ErrEmptySecret, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tc.wantCode {
+				t.Fatalf("got status %d, want %d", resp.StatusCode, tc.wantCode)
+			}
+			if tc.wantCode == http.StatusOK && !capturedTime.Equal(fakeNow) {
+				t.Errorf("time: got %v, want %v", capturedTime, fakeNow)
+			}
+		})
+	}
+}
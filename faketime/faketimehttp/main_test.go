@@ -0,0 +1,16 @@
+package faketimehttp
+
+import (
+	"os"
+	"testing"
+
+	"github.com/akm/time/faketime"
+)
+
+func TestMain(m *testing.M) {
+	// Tests which check the disabled behavior override this with t.Setenv.
+	if err := os.Setenv(faketime.EnvEnabled, "true"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
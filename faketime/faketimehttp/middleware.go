@@ -5,39 +5,178 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/akm/time"
 	"github.com/akm/time/faketime"
 )
 
-func Middleware(filePath string, layout string) func(next http.Handler) http.Handler {
+const (
+	HeaderFakeTimeNow    = "X-Fake-Time-Now"
+	HeaderFakeTimeSpec   = "X-Fake-Time-Spec"
+	HeaderFakeTimeSource = "X-Fake-Time-Source"
+)
+
+type middlewareConfig struct {
+	responseHeaders bool
+	dateHeader      bool
+	scriptInjection bool
+	secret          []byte
+}
+
+type MiddlewareOption func(*middlewareConfig)
+
+func newMiddlewareConfig(opts []MiddlewareOption) *middlewareConfig {
+	cfg := &middlewareConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithResponseHeaders adds X-Fake-Time-* headers to responses while fake time is active.
+func WithResponseHeaders() MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.responseHeaders = true
+	}
+}
+
+// WithDateHeader sets the Date header to the fake time while fake time is active.
+// Without it, net/http sets the Date header from the real clock.
+func WithDateHeader() MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.dateHeader = true
+	}
+}
+
+// Middleware applies the fake time written in the file at filePath to each request.
+// A spec without "since" is anchored at the modification time of the file.
+// Unless faketime.Enabled returns true, it passes requests through and ignores the file.
+func Middleware(filePath string, layout string, opts ...MiddlewareOption) func(next http.Handler) http.Handler {
 	provider := faketime.NewFileProvider(filePath)
 
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
+	if !faketime.Enabled() {
+		if s, _ := provider.Get(context.Background()); s != "" {
+			slog.Error("faketime file is IGNORED because faketime is not enabled", "file", filePath, "content", s, "env", faketime.EnvEnabled)
+		}
+		return passThrough
+	}
+
+	return newMiddleware("file", http.StatusInternalServerError, newMiddlewareConfig(opts), func(r *http.Request) (*faketime.FakeTime, error) {
+		ctx := r.Context()
 
-			s, err := provider.Get(ctx)
+		s, err := provider.Get(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to get faketime", "error", err, "file", filePath)
+			return nil, err
+		}
+
+		if s == "" {
+			return nil, nil
+		}
+
+		ft, err := faketime.Parse(s, layout)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to parse faketime", "error", err, "file", filePath, "content", s)
+			return nil, err
+		}
+		ft.Source = time.ClockSourceFile
+		if ft.Anchor.IsZero() {
+			// Anchor it at the file, or a flowing spec restarts at every request.
+			anchor, err := provider.Anchor(ctx)
 			if err != nil {
-				slog.ErrorContext(ctx, "failed to get faketime", "error", err, "file", filePath)
-				http.Error(w, "faketime error", http.StatusInternalServerError)
-				return
+				slog.ErrorContext(ctx, "failed to get faketime anchor", "error", err, "file", filePath)
+				return nil, err
 			}
+			ft.Anchor = anchor
+		}
+		return ft, nil
+	})
+}
 
-			if s == "" {
-				next.ServeHTTP(w, r)
+// newMiddleware applies the fake time which resolve returns.
+// resolve returns nil without error when fake time is not requested.
+func newMiddleware(source string, errorCode int, cfg *middlewareConfig, resolve func(r *http.Request) (*faketime.FakeTime, error)) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ft, err := resolve(r)
+			if err != nil {
+				http.Error(w, "faketime error", errorCode)
 				return
 			}
 
-			ft, err := faketime.Parse(s, layout)
-			if err != nil {
-				slog.ErrorContext(ctx, "failed to parse faketime", "error", err, "file", filePath, "content", s)
-				http.Error(w, "faketime error", http.StatusInternalServerError)
+			if ft == nil {
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			_ = ft.Run(ctx, func(ctx context.Context) error {
-				next.ServeHTTP(w, r)
+			_ = ft.Run(r.Context(), func(ctx context.Context) error {
+				if cfg.responseHeaders || cfg.dateHeader {
+					hw := &headerWriter{ResponseWriter: w, before: func(h http.Header) {
+						now := time.Now()
+						if cfg.responseHeaders {
+							h.Set(HeaderFakeTimeNow, now.Format(time.RFC3339Nano))
+							h.Set(HeaderFakeTimeSpec, ft.Spec)
+							h.Set(HeaderFakeTimeSource, source)
+						}
+						if cfg.dateHeader {
+							h.Set("Date", now.UTC().Format(http.TimeFormat))
+						}
+					}}
+					defer hw.flushHeader()
+					w = hw
+				}
+				if cfg.scriptInjection && r.Method != http.MethodHead {
+					iw := &injectWriter{ResponseWriter: w, script: func() string { return script(ft) }}
+					defer iw.finish()
+					w = iw
+				}
+				next.ServeHTTP(w, r.WithContext(ctx))
 				return nil
 			})
 		})
 	}
 }
+
+func passThrough(next http.Handler) http.Handler {
+	return next
+}
+
+// headerWriter calls before just once right before the header is written.
+type headerWriter struct {
+	http.ResponseWriter
+	before      func(http.Header)
+	wroteHeader bool
+}
+
+func (w *headerWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.before(w.Header())
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *headerWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *headerWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *headerWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// flushHeader writes the header while the fake time is still active
+// even if the handler returns without writing anything.
+func (w *headerWriter) flushHeader() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -1,13 +1,17 @@
 package faketimehttp
 
 import (
+	"bytes"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/akm/time"
+	"github.com/akm/time/faketime"
 )
 
 func TestMiddleware(t *testing.T) {
@@ -532,3 +536,195 @@ func TestMiddleware(t *testing.T) {
 		}
 	})
 }
+
+func TestMiddleware_ResponseHeaders(t *testing.T) {
+	newServer := func(t *testing.T, content string, opts ...MiddlewareOption) *httptest.Server {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "time.txt")
+		if content != "" {
+			if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		server := httptest.NewServer(Middleware(filePath, time.DateTime, opts...)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/empty" {
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			}),
+		))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	get := func(t *testing.T, url string) *http.Response {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		return resp
+	}
+
+	fakeNow := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		content      string
+		opts         []MiddlewareOption
+		path         string
+		wantHeaders  bool
+		wantFakeDate bool
+	}{
+		{
+			name:    "no options",
+			content: "2023-06-15 10:30:00",
+			path:    "/",
+		},
+		{
+			name:        "response headers",
+			content:     "2023-06-15 10:30:00",
+			opts:        []MiddlewareOption{WithResponseHeaders()},
+			path:        "/",
+			wantHeaders: true,
+		},
+		{
+			name:         "date header",
+			content:      "2023-06-15 10:30:00",
+			opts:         []MiddlewareOption{WithDateHeader()},
+			path:         "/",
+			wantFakeDate: true,
+		},
+		{
+			name:         "both options with handler writing nothing",
+			content:      "2023-06-15 10:30:00",
+			opts:         []MiddlewareOption{WithResponseHeaders(), WithDateHeader()},
+			path:         "/empty",
+			wantHeaders:  true,
+			wantFakeDate: true,
+		},
+		{
+			name: "fake time inactive",
+			opts: []MiddlewareOption{WithResponseHeaders(), WithDateHeader()},
+			path: "/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(t, tt.content, tt.opts...)
+			resp := get(t, server.URL+tt.path)
+
+			if tt.wantHeaders {
+				now, err := time.Parse(time.RFC3339Nano, resp.Header.Get(HeaderFakeTimeNow))
+				if err != nil {
+					t.Fatalf("failed to parse %s: %v", HeaderFakeTimeNow, err)
+				}
+				if !now.Equal(fakeNow) {
+					t.Errorf("%s = %v, want %v", HeaderFakeTimeNow, now, fakeNow)
+				}
+				if got := resp.Header.Get(HeaderFakeTimeSpec); got != tt.content {
+					t.Errorf("%s = %q, want %q", HeaderFakeTimeSpec, got, tt.content)
+				}
+				if got := resp.Header.Get(HeaderFakeTimeSource); got != "file" {
+					t.Errorf("%s = %q, want %q", HeaderFakeTimeSource, got, "file")
+				}
+			} else {
+				for _, key := range []string{HeaderFakeTimeNow, HeaderFakeTimeSpec, HeaderFakeTimeSource} {
+					if got := resp.Header.Get(key); got != "" {
+						t.Errorf("%s = %q, want empty", key, got)
+					}
+				}
+			}
+
+			date, err := http.ParseTime(resp.Header.Get("Date"))
+			if err != nil {
+				t.Fatalf("failed to parse Date: %v", err)
+			}
+			if got := date.Equal(fakeNow); got != tt.wantFakeDate {
+				t.Errorf("Date = %v, fake: %v, want fake: %v", date, got, tt.wantFakeDate)
+			}
+		})
+	}
+}
+
+func TestMiddleware_Anchor(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "time.txt")
+	if err := os.WriteFile(filePath, []byte("2023-06-15 10:30:00 for=1m; 2023-06-15 12:00:00 x2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The script was written an hour ago, so it's past the first step whenever it's read.
+	modTime := time.StdNow().Add(-time.Hour)
+	if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	var captured []time.Time
+	handler := Middleware(filePath, time.DateTime)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			captured = append(captured, time.Now())
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+		}
+	}
+
+	// 59 minutes at x2 have passed in the second step.
+	want := time.Date(2023, 6, 15, 13, 58, 0, 0, time.UTC)
+	for i, got := range captured {
+		if got.Before(want) || got.After(want.Add(time.Minute)) {
+			t.Errorf("request %d: time.Now() = %v, want just after %v", i, got, want)
+		}
+	}
+}
+
+func TestMiddleware_NotEnabled(t *testing.T) {
+	t.Setenv(faketime.EnvEnabled, "false")
+	if faketime.Enabled() {
+		t.Skip("faketime is enabled by the build tag")
+	}
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "time.txt")
+	if err := os.WriteFile(filePath, []byte("2023-06-15 10:30:00"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	realNow := time.Now()
+	var capturedTime time.Time
+	handler := Middleware(filePath, time.DateTime)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			capturedTime = time.Now()
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	if !strings.Contains(logs.String(), "level=ERROR") || !strings.Contains(logs.String(), filePath) {
+		t.Errorf("an error should be logged for the ignored file, logs: %s", logs.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if capturedTime.Before(realNow) {
+		t.Errorf("time.Now() = %v, want real time after %v", capturedTime, realNow)
+	}
+}
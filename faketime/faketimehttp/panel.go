@@ -0,0 +1,48 @@
+package faketimehttp
+
+import (
+	_ "embed"
+	"net/http"
+	"strings"
+
+	"github.com/akm/time/faketime"
+)
+
+//go:embed panel.html
+var panelHTML []byte
+
+// PanelHandler serves a self-contained HTML page to control file from a browser.
+// Requests to a path ending with "/api" are handled by AdminHandler, which the page talks to.
+func PanelHandler(file *faketime.File, opts ...AdminOption) http.Handler {
+	cfg := &adminConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	admin := AdminHandler(file, opts...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/api") {
+			admin.ServeHTTP(w, r)
+			return
+		}
+
+		if cfg.protected() && !cfg.authorized(r) {
+			if cfg.username != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="faketime"`)
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+		_, _ = w.Write(panelHTML)
+	})
+}
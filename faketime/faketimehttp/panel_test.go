@@ -0,0 +1,89 @@
+package faketimehttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+func TestPanelHandler(t *testing.T) {
+	t.Run("serves HTML without external assets", func(t *testing.T) {
+		handler := PanelHandler(faketime.NewFile(filepath.Join(t.TempDir(), "time.txt"), time.DateTime))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("Content-Type = %q, want text/html", ct)
+		}
+		body := rec.Body.String()
+		for _, s := range []string{"<script src", "<link", "http://", "https://"} {
+			if strings.Contains(body, s) {
+				t.Errorf("body should not contain %q", s)
+			}
+		}
+	})
+
+	t.Run("delegates API requests", func(t *testing.T) {
+		file := faketime.NewFile(filepath.Join(t.TempDir(), "time.txt"), time.DateTime)
+		handler := PanelHandler(file)
+
+		body := `{"time": "2024-01-31T23:59:00+09:00", "ratio": 60}`
+		req := httptest.NewRequest(http.MethodPut, "/faketime/api", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+
+		content, err := os.ReadFile(file.FilePath)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		if got, want := scrubAnchor(string(content)), "2024-01-31 14:59:00 x60 since=<anchor>"; got != want {
+			t.Errorf("file content = %q, want %q", got, want)
+		}
+
+		var state AdminState
+		if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		// The fake time flows at x60 while the request is served.
+		if want := time.Date(2024, 1, 31, 23, 59, 0, 0, time.FixedLocation); state.Now.Before(want) || state.Now.After(want.Add(time.Minute)) {
+			t.Errorf("now = %v, want %v (+1m)", state.Now, want)
+		}
+	})
+
+	t.Run("authentication", func(t *testing.T) {
+		handler := PanelHandler(faketime.NewFile(filepath.Join(t.TempDir(), "time.txt"), time.DateTime), WithBasicAuth("admin", "secret"))
+
+		for _, path := range []string{"/", "/api"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("%s: got status %d, want %d", path, rec.Code, http.StatusUnauthorized)
+			}
+
+			req = httptest.NewRequest(http.MethodGet, path, nil)
+			req.SetBasicAuth("admin", "secret")
+			rec = httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s: got status %d, want %d", path, rec.Code, http.StatusOK)
+			}
+		}
+	})
+}
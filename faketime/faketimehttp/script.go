@@ -0,0 +1,138 @@
+package faketimehttp
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+// scriptTemplate replaces Date in browsers so that it starts from the fake time
+// when the script is loaded and proceeds at the ratio.
+const scriptTemplate = `(function () {
+  "use strict";
+  var RealDate = window.__faketimeRealDate || Date;
+  var fake = %d, ratio = %s, start = RealDate.now();
+  function now() { return Math.floor(fake + (RealDate.now() - start) * ratio); }
+  function FakeDate() {
+    if (!new.target) { return new RealDate(now()).toString(); }
+    var args = arguments.length ? Array.prototype.slice.call(arguments) : [now()];
+    return Reflect.construct(RealDate, args, new.target);
+  }
+  FakeDate.prototype = RealDate.prototype;
+  Object.setPrototypeOf(FakeDate, RealDate);
+  FakeDate.now = now;
+  window.__faketimeRealDate = RealDate;
+  window.Date = FakeDate;
+})();
+`
+
+// script must be called while the fake time is active.
+func script(ft *faketime.FakeTime) string {
+	return fmt.Sprintf(scriptTemplate, time.Now().UnixMilli(), strconv.FormatFloat(ft.Ratio, 'f', -1, 64))
+}
+
+// ScriptHandler serves JavaScript which makes Date in browsers follow the fake time.
+// It must be wrapped by Middleware or HeaderMiddleware, otherwise it serves a script doing nothing.
+func ScriptHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		ft, ok := faketime.FromContext(r.Context())
+		if !ok {
+			_, _ = w.Write([]byte("/* faketime is not active */\n"))
+			return
+		}
+		_, _ = w.Write([]byte(script(ft)))
+	})
+}
+
+// WithScriptInjection inserts the script which ScriptHandler serves into HTML responses
+// while fake time is active. Compressed responses are left as they are.
+func WithScriptInjection() MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.scriptInjection = true
+	}
+}
+
+var (
+	headTagPattern = regexp.MustCompile(`(?i)<head(\s[^>]*)?>`)
+	htmlTagPattern = regexp.MustCompile(`(?i)<html(\s[^>]*)?>`)
+)
+
+func injectScript(body []byte, js string) []byte {
+	tag := []byte("<script>" + js + "</script>")
+	pos := 0
+	if loc := headTagPattern.FindIndex(body); loc != nil {
+		pos = loc[1]
+	} else if loc := htmlTagPattern.FindIndex(body); loc != nil {
+		pos = loc[1]
+	}
+	result := make([]byte, 0, len(body)+len(tag))
+	result = append(result, body[:pos]...)
+	result = append(result, tag...)
+	return append(result, body[pos:]...)
+}
+
+// injectWriter buffers an HTML response to insert the script into it in finish.
+type injectWriter struct {
+	http.ResponseWriter
+	script      func() string
+	code        int
+	buf         *bytes.Buffer
+	wroteHeader bool
+}
+
+func (w *injectWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.Header()
+	if strings.HasPrefix(h.Get("Content-Type"), "text/html") && h.Get("Content-Encoding") == "" && code != http.StatusNoContent && code != http.StatusNotModified {
+		h.Del("Content-Length")
+		w.code = code
+		w.buf = &bytes.Buffer{}
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *injectWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			// net/http sniffs the content type on the first write in the same way.
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buf != nil {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *injectWriter) Flush() {
+	// A buffered HTML response is written in finish.
+	if w.buf != nil {
+		return
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *injectWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *injectWriter) finish() {
+	if w.buf == nil {
+		return
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+	_, _ = w.ResponseWriter.Write(injectScript(w.buf.Bytes(), w.script()))
+}
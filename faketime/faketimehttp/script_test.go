@@ -0,0 +1,191 @@
+package faketimehttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/akm/time"
+)
+
+func TestScriptHandler(t *testing.T) {
+	fakeNow := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)
+
+	t.Run("with fake time", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "time.txt")
+		if err := os.WriteFile(filePath, []byte("2023-06-15 10:30:00 x2"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		handler := Middleware(filePath, time.DateTime)(ScriptHandler())
+
+		req := httptest.NewRequest(http.MethodGet, "/faketime.js", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/javascript") {
+			t.Errorf("Content-Type = %q, want text/javascript", ct)
+		}
+		body := rec.Body.String()
+		m := regexp.MustCompile(`var fake = (\d+), ratio = 2,`).FindStringSubmatch(body)
+		if m == nil {
+			t.Fatalf("body should contain the fake time and the ratio, body: %s", body)
+		}
+		fake, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The spec is anchored at the file, which is written right before the request.
+		if got := time.UnixMilli(fake); got.Before(fakeNow) || got.After(fakeNow.Add(time.Minute)) {
+			t.Errorf("fake = %v, want just after %v", got, fakeNow)
+		}
+	})
+
+	t.Run("without fake time", func(t *testing.T) {
+		handler := ScriptHandler()
+
+		req := httptest.NewRequest(http.MethodGet, "/faketime.js", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+		}
+		if strings.Contains(rec.Body.String(), "window.Date") {
+			t.Errorf("body should not replace Date, body: %s", rec.Body.String())
+		}
+	})
+}
+
+func TestMiddleware_ScriptInjection(t *testing.T) {
+	fakeNow := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)
+	scriptTag := "<script>(function () {"
+
+	tests := []struct {
+		name        string
+		content     string
+		contentType string
+		encoding    string
+		body        string
+		wantBody    func(t *testing.T, body string)
+	}{
+		{
+			name:        "after head tag",
+			content:     "2023-06-15 10:30:00",
+			contentType: "text/html; charset=utf-8",
+			body:        `<!DOCTYPE html><html><head lang="en"><title>x</title></head><body></body></html>`,
+			wantBody: func(t *testing.T, body string) {
+				if !strings.HasPrefix(body, `<!DOCTYPE html><html><head lang="en">`+scriptTag) {
+					t.Errorf("script should be inserted after <head>, body: %s", body)
+				}
+				if want := "var fake = " + strconv.FormatInt(fakeNow.UnixMilli(), 10); !strings.Contains(body, want) {
+					t.Errorf("body should contain %q, body: %s", want, body)
+				}
+			},
+		},
+		{
+			name:        "after html tag without head",
+			content:     "2023-06-15 10:30:00",
+			contentType: "text/html",
+			body:        `<HTML><body></body></HTML>`,
+			wantBody: func(t *testing.T, body string) {
+				if !strings.HasPrefix(body, `<HTML>`+scriptTag) {
+					t.Errorf("script should be inserted after <html>, body: %s", body)
+				}
+			},
+		},
+		{
+			name:    "sniffed HTML",
+			content: "2023-06-15 10:30:00",
+			body:    `<html><head></head><body></body></html>`,
+			wantBody: func(t *testing.T, body string) {
+				if !strings.HasPrefix(body, `<html><head>`+scriptTag) {
+					t.Errorf("script should be inserted after <head>, body: %s", body)
+				}
+			},
+		},
+		{
+			name:        "JSON is not modified",
+			content:     "2023-06-15 10:30:00",
+			contentType: "application/json",
+			body:        `{"html": "<head></head>"}`,
+			wantBody: func(t *testing.T, body string) {
+				if body != `{"html": "<head></head>"}` {
+					t.Errorf("body should not be modified, body: %s", body)
+				}
+			},
+		},
+		{
+			name:        "compressed HTML is not modified",
+			content:     "2023-06-15 10:30:00",
+			contentType: "text/html",
+			encoding:    "identity-for-test",
+			body:        `<html><head></head></html>`,
+			wantBody: func(t *testing.T, body string) {
+				if body != `<html><head></head></html>` {
+					t.Errorf("body should not be modified, body: %s", body)
+				}
+			},
+		},
+		{
+			name:        "fake time inactive",
+			contentType: "text/html",
+			body:        `<html><head></head></html>`,
+			wantBody: func(t *testing.T, body string) {
+				if body != `<html><head></head></html>` {
+					t.Errorf("body should not be modified, body: %s", body)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "time.txt")
+			if tt.content != "" {
+				if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			handler := Middleware(filePath, time.DateTime, WithScriptInjection())(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if tt.contentType != "" {
+						w.Header().Set("Content-Type", tt.contentType)
+					}
+					if tt.encoding != "" {
+						w.Header().Set("Content-Encoding", tt.encoding)
+					}
+					w.Header().Set("Content-Length", strconv.Itoa(len(tt.body)))
+					for _, chunk := range strings.SplitAfter(tt.body, ">") {
+						_, _ = w.Write([]byte(chunk))
+					}
+				}),
+			)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.wantBody(t, string(body))
+		})
+	}
+}
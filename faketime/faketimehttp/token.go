@@ -0,0 +1,66 @@
+package faketimehttp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/akm/time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid faketime token")
+	ErrExpiredToken = errors.New("expired faketime token")
+	ErrEmptySecret  = errors.New("empty faketime secret")
+)
+
+// SignToken returns a token which holds value and expiresAt with an HMAC-SHA256 signature
+// over them and name, which is the header the token is for. A spec is signed for HeaderFakeTime
+// even when it's sent in CookieName, and an anchor for HeaderFakeTimeAnchor.
+// Test clients send it to servers using WithSecret.
+func SignToken(secret []byte, name, value string, expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value)) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(tokenMAC(secret, name, payload))
+}
+
+// VerifyToken returns the value in token if the signature for name is valid and it hasn't expired at now.
+func VerifyToken(secret []byte, name, token string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed signature: %v", ErrInvalidToken, err)
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal(mac, tokenMAC(secret, name, payload)) {
+		return "", fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed expiry: %v", ErrInvalidToken, err)
+	}
+	if now.Unix() >= expiresAt {
+		return "", fmt.Errorf("%w: expired at %s", ErrExpiredToken, time.Unix(expiresAt, 0).UTC().Format(time.RFC3339))
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed value: %v", ErrInvalidToken, err)
+	}
+	return string(value), nil
+}
+
+func tokenMAC(secret []byte, name, payload string) []byte {
+	h := hmac.New(sha256.New, secret)
+	// Header names can't contain newlines, so the name and the payload can't be mixed up.
+	_, _ = h.Write([]byte(name + "\n" + payload))
+	return h.Sum(nil)
+}
@@ -0,0 +1,111 @@
+package faketimehttp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/akm/time"
+)
+
+func TestSignToken(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	token := SignToken(secret, HeaderFakeTime, "2024-12-31T23:59:00Z x60", now.Add(time.Minute))
+
+	tamper := func(i int, v string) string {
+		parts := strings.Split(token, ".")
+		parts[i] = v
+		return strings.Join(parts, ".")
+	}
+
+	tests := []struct {
+		name    string
+		secret  []byte
+		header  string
+		token   string
+		now     time.Time
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "valid token",
+			secret: secret,
+			token:  token,
+			now:    now,
+			want:   "2024-12-31T23:59:00Z x60",
+		},
+		{
+			name:    "expired token",
+			secret:  secret,
+			token:   token,
+			now:     now.Add(time.Minute),
+			wantErr: ErrExpiredToken,
+		},
+		{
+			name:    "wrong secret",
+			secret:  []byte("other"),
+			token:   token,
+			now:     now,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "other header",
+			secret:  secret,
+			header:  HeaderFakeTimeAnchor,
+			token:   token,
+			now:     now,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "tampered value",
+			secret:  secret,
+			token:   tamper(0, "MjAyNC0wMS0wMVQwMDowMDowMFo"),
+			now:     now,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "extended expiry",
+			secret:  secret,
+			token:   tamper(1, "9999999999"),
+			now:     now,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "unsigned spec",
+			secret:  secret,
+			token:   "2024-12-31T23:59:00Z x60",
+			now:     now,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "malformed signature",
+			secret:  secret,
+			token:   tamper(2, "!!!"),
+			now:     now,
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == "" {
+				header = HeaderFakeTime
+			}
+			got, err := VerifyToken(tt.secret, header, tt.token, tt.now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package faketime
 
 import (
 	"os"
+	"path/filepath"
 
 	"github.com/akm/time"
 )
@@ -18,20 +19,39 @@ func NewFile(filePath string, layout string) *File {
 	}
 }
 
-func (f *File) Save(t time.Time) (rerr error) {
-	content := t.Format(f.layout)
-	file, err := os.OpenFile(f.FilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+func (f *File) Layout() string {
+	return f.layout
+}
+
+func (f *File) Save(t time.Time) error {
+	return f.SaveSpec(t.Format(f.layout))
+}
+
+// SaveSpec writes spec as is. Callers are expected to validate it with Parse beforehand.
+// The file is replaced atomically so that readers never see a partially written spec.
+func (f *File) SaveSpec(spec string) (rerr error) {
+	file, err := os.CreateTemp(filepath.Dir(f.FilePath), "."+filepath.Base(f.FilePath)+".*")
 	if err != nil {
 		return err
 	}
 	defer func() {
-		rerr = file.Close()
+		if rerr != nil {
+			_ = os.Remove(file.Name())
+		}
 	}()
-	if _, err = file.WriteString(content); err != nil {
-		rerr = err
-		return
+
+	if _, err := file.WriteString(spec); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Chmod(0o644); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
 	}
-	return
+	return os.Rename(file.Name(), f.FilePath)
 }
 
 func (f *File) Delete() error {
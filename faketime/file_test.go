@@ -152,3 +152,54 @@ func TestFile_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestFile_SaveSpec(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "faketime.txt")
+
+	f := NewFile(filePath, "2006-01-02 15:04:05")
+	if err := f.SaveSpec("2024-01-02 15:04:05 x2"); err != nil {
+		t.Fatalf("SaveSpec() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	wantContent := "2024-01-02 15:04:05 x2"
+	if string(content) != wantContent {
+		t.Errorf("file content = %v, want %v", string(content), wantContent)
+	}
+}
+
+func TestFile_SaveSpec_LeavesNoTemporaryFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	f := NewFile(filepath.Join(tmpDir, "faketime.txt"), "2006-01-02 15:04:05")
+
+	for _, spec := range []string{"2024-01-02 15:04:05", "2024-01-03 15:04:05 x2"} {
+		if err := f.SaveSpec(spec); err != nil {
+			t.Fatalf("SaveSpec() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "faketime.txt" {
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("files in directory = %v, want [faketime.txt]", names)
+	}
+
+	info, err := os.Stat(f.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("file mode = %v, want %v", info.Mode().Perm(), os.FileMode(0o644))
+	}
+}
@@ -6,17 +6,38 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	orig "time"
+
+	"github.com/akm/time"
 )
 
 type Provider interface {
 	Get(ctx context.Context) (string, error)
 }
 
+// SourceProvider is a Provider which tells where its specs come from,
+// which time.ClockInfo reports.
+type SourceProvider interface {
+	Provider
+	Source() time.ClockSource
+}
+
+// AnchorProvider is a Provider which tells when its specs were given.
+// Specs without "since" are anchored at it, so that a flowing spec or a script
+// doesn't restart whenever it's read again.
+type AnchorProvider interface {
+	Provider
+	Anchor(ctx context.Context) (orig.Time, error)
+}
+
 type FileProvider struct {
 	filePath string
 }
 
-var _ Provider = (*FileProvider)(nil)
+var (
+	_ SourceProvider = (*FileProvider)(nil)
+	_ AnchorProvider = (*FileProvider)(nil)
+)
 
 func NewFileProvider(filePath string) *FileProvider {
 	return &FileProvider{filePath: filePath}
@@ -46,3 +67,37 @@ func (p *FileProvider) Get(ctx context.Context) (string, error) {
 	}
 	return strings.TrimSpace(string(data)), nil
 }
+
+func (p *FileProvider) Source() time.ClockSource {
+	return time.ClockSourceFile
+}
+
+// Anchor returns the modification time of the file, which is when the spec was written.
+func (p *FileProvider) Anchor(ctx context.Context) (orig.Time, error) {
+	stat, err := os.Stat(p.filePath)
+	if err != nil {
+		return orig.Time{}, fmt.Errorf("%w: %v", ErrFileRead, err)
+	}
+	return stat.ModTime(), nil
+}
+
+// EnvSpec is the environment variable which `faketime run` sets for child processes.
+const EnvSpec = "FAKETIME_SPEC"
+
+type EnvProvider struct {
+	name string
+}
+
+var _ SourceProvider = (*EnvProvider)(nil)
+
+func NewEnvProvider(name string) *EnvProvider {
+	return &EnvProvider{name: name}
+}
+
+func (p *EnvProvider) Get(ctx context.Context) (string, error) {
+	return strings.TrimSpace(os.Getenv(p.name)), nil
+}
+
+func (p *EnvProvider) Source() time.ClockSource {
+	return time.ClockSourceEnv
+}
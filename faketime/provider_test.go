@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/akm/time"
 )
 
 func TestNewFileProvider(t *testing.T) {
@@ -129,3 +131,52 @@ func TestFileProvider_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestFileProvider_Anchor(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "time.txt")
+	if err := os.WriteFile(filePath, []byte("2024-01-02 15:04:05 x2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewFileProvider(filePath).Anchor(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Equal(modTime) {
+		t.Errorf("Anchor() = %v, want %v", got, modTime)
+	}
+
+	if _, err := NewFileProvider(filepath.Join(t.TempDir(), "nonexistent.txt")).Anchor(context.Background()); !errors.Is(err, ErrFileRead) {
+		t.Errorf("Anchor() error = %v, want %v", err, ErrFileRead)
+	}
+}
+
+func TestEnvProvider_Get(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "not set", value: "", want: ""},
+		{name: "spec", value: "2024-01-02 15:04:05 x2", want: "2024-01-02 15:04:05 x2"},
+		{name: "whitespace is trimmed", value: "  2024-01-02 15:04:05\n", want: "2024-01-02 15:04:05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvSpec, tt.value)
+
+			got, err := NewEnvProvider(EnvSpec).Get(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package faketime
 
 import (
 	"context"
+	"fmt"
 )
 
 type Runner struct {
@@ -26,15 +27,39 @@ func (r *Runner) Build(ctx context.Context) (*FakeTime, error) {
 	if err != nil {
 		return nil, err
 	}
+	if p, ok := r.provider.(SourceProvider); ok {
+		fakeTime.Source = p.Source()
+	}
+	if p, ok := r.provider.(AnchorProvider); ok && fakeTime.Anchor.IsZero() {
+		anchor, err := p.Anchor(ctx)
+		if err != nil {
+			return nil, err
+		}
+		fakeTime.Anchor = anchor
+	}
 
 	return fakeTime, nil
 }
 
+// Start calls fn with the fake time which the provider gives.
+// The Clock is available in the context with ClockFromContext.
+// If faketime is not enabled, it calls fn with the real time, or returns ErrNotEnabled
+// when the provider gives a spec, so that a stray faketime file stops the startup.
 func (r *Runner) Start(ctx context.Context, fn func(context.Context) error) error {
+	if !Enabled() {
+		s, err := r.provider.Get(ctx)
+		if err != nil {
+			return err
+		}
+		if s != "" {
+			return fmt.Errorf("%w: set %s=true or build with -tags %s to use %q", ErrNotEnabled, EnvEnabled, BuildTag, s)
+		}
+		return fn(ctx)
+	}
+
 	fakeTime, err := r.Build(ctx)
 	if err != nil {
 		return err
 	}
-	defer fakeTime.Setup(ctx)()
-	return fn(ctx)
+	return fakeTime.Run(ctx, fn)
 }
@@ -3,6 +3,8 @@ package faketime
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/akm/time"
@@ -135,7 +137,71 @@ func TestRunner_Build(t *testing.T) {
 	}
 }
 
+func TestRunner_Build_Source(t *testing.T) {
+	t.Setenv("FAKETIME_TEST_SPEC", "2024-01-02 15:04:05")
+
+	ft, err := NewRunner(NewEnvProvider("FAKETIME_TEST_SPEC"), "2006-01-02 15:04:05").Build(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ft.Source != time.ClockSourceEnv {
+		t.Errorf("Source = %q, want %q", ft.Source, time.ClockSourceEnv)
+	}
+
+	ft, err = NewRunner(&mockProvider{value: "2024-01-02 15:04:05"}, "2006-01-02 15:04:05").Build(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ft.Source != "" {
+		t.Errorf("Source = %q, want empty for a provider without Source", ft.Source)
+	}
+}
+
+func TestRunner_Build_Anchor(t *testing.T) {
+	layout := "2006-01-02 15:04:05"
+	modTime := time.StdNow().Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		spec       string
+		wantAnchor time.Time
+	}{
+		{
+			name:       "without since",
+			spec:       "2024-01-02 15:04:05 x2",
+			wantAnchor: modTime,
+		},
+		{
+			name:       "with since",
+			spec:       "2024-01-02 15:04:05 x2 since=2024-06-15T03:00:00Z",
+			wantAnchor: time.Date(2024, 6, 15, 3, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "time.txt")
+			if err := os.WriteFile(filePath, []byte(tt.spec), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+
+			ft, err := NewRunner(NewFileProvider(filePath), layout).Build(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ft.Anchor.Equal(tt.wantAnchor) {
+				t.Errorf("Anchor = %v, want %v", ft.Anchor, tt.wantAnchor)
+			}
+		})
+	}
+}
+
 func TestRunner_Start(t *testing.T) {
+	t.Setenv(EnvEnabled, "true")
+
 	layout := "2006-01-02 15:04:05"
 	providerErr := errors.New("provider error")
 	fnErr := errors.New("fn error")
@@ -260,3 +326,51 @@ func TestRunner_Start(t *testing.T) {
 		})
 	}
 }
+
+func TestRunner_Start_NotEnabled(t *testing.T) {
+	t.Setenv(EnvEnabled, "false")
+	if Enabled() {
+		t.Skip("faketime is enabled by the build tag")
+	}
+
+	t.Run("empty string from provider runs fn with real time", func(t *testing.T) {
+		runner := NewRunner(&mockProvider{value: ""}, "2006-01-02 15:04:05")
+		var called bool
+		err := runner.Start(context.Background(), func(ctx context.Context) error {
+			called = true
+			if _, ok := FromContext(ctx); ok {
+				t.Error("fake time should not be in context")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !called {
+			t.Error("fn should be called")
+		}
+	})
+
+	t.Run("spec from provider returns error", func(t *testing.T) {
+		runner := NewRunner(&mockProvider{value: "2024-01-02 15:04:05"}, "2006-01-02 15:04:05")
+		err := runner.Start(context.Background(), func(ctx context.Context) error {
+			t.Error("fn should not be called")
+			return nil
+		})
+		if !errors.Is(err, ErrNotEnabled) {
+			t.Fatalf("expected error %v, got %v", ErrNotEnabled, err)
+		}
+	})
+
+	t.Run("provider error is returned", func(t *testing.T) {
+		providerErr := errors.New("provider error")
+		runner := NewRunner(&mockProvider{err: providerErr}, "2006-01-02 15:04:05")
+		err := runner.Start(context.Background(), func(ctx context.Context) error {
+			t.Error("fn should not be called")
+			return nil
+		})
+		if !errors.Is(err, providerErr) {
+			t.Fatalf("expected error %v, got %v", providerErr, err)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package faketime
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scriptSeparator is written by Format between steps. It keeps a script in a line
+// so that it can be sent in a header or an environment variable.
+const scriptSeparator = "; "
+
+func isScript(s string) bool {
+	return strings.ContainsAny(s, ";\n")
+}
+
+// parseScript reads steps separated by newlines or ";". Empty lines and lines
+// starting with "#" are ignored. Every step but the last one has "for=<duration>",
+// which is how long the step lasts in the real time. For example:
+//
+//	2024-03-30 23:00:00 x60 for=5m
+//	2024-03-31 01:59:00
+//
+// "since=" is allowed only in the first step. Without it, the script starts when
+// the fake time is set up, so a file read on each request needs it to share the timeline.
+func parseScript(s string, layout string) (*FakeTime, error) {
+	var steps []*FakeTime
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ';' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		step, err := parseStep(line, layout)
+		if err != nil {
+			return nil, err
+		}
+		if step.Increment != 0 {
+			return nil, fmt.Errorf("%w: increment cannot be used in a script in file content: %s", ErrInvalidFaketimeFileContent, s)
+		}
+		if len(steps) > 0 && !step.Anchor.IsZero() {
+			return nil, fmt.Errorf("%w: %s is allowed only in the first step in file content: %s", ErrInvalidFaketimeFileContent, optionSince, s)
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("%w: no step in file content: %s", ErrInvalidFaketimeFileContent, s)
+	}
+
+	for i, step := range steps {
+		last := i == len(steps)-1
+		if !last && step.For == 0 {
+			return nil, fmt.Errorf("%w: step %q needs %s in file content: %s", ErrInvalidFaketimeFileContent, step.Spec, optionFor, s)
+		}
+		if last && step.For != 0 {
+			return nil, fmt.Errorf("%w: %s needs a following step in file content: %s", ErrInvalidFaketimeFileContent, optionFor, s)
+		}
+		if !last {
+			step.Then = steps[i+1]
+		}
+		step.Spec = ""
+	}
+	steps[0].Spec = s
+	return steps[0], nil
+}
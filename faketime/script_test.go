@@ -0,0 +1,103 @@
+package faketime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akm/time"
+)
+
+func TestParse_Script(t *testing.T) {
+	layout := "2006-01-02 15:04:05"
+
+	t.Run("steps", func(t *testing.T) {
+		s := "# soak test\n2024-03-30 23:00:00 x60 for=5m\n\n2024-03-31 01:59:00\n"
+		ft, err := Parse(s, layout)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ft.Time.Equal(time.Date(2024, 3, 30, 23, 0, 0, 0, time.UTC)) || ft.Ratio != 60 || ft.For != 5*time.Minute {
+			t.Errorf("first step = %+v", ft)
+		}
+		if ft.Spec != s {
+			t.Errorf("Spec = %q, want %q", ft.Spec, s)
+		}
+		if ft.Then == nil || !ft.Then.Time.Equal(time.Date(2024, 3, 31, 1, 59, 0, 0, time.UTC)) || ft.Then.Ratio != 0 || ft.Then.Then != nil {
+			t.Errorf("second step = %+v", ft.Then)
+		}
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		s := "2024-03-30 23:00:00 x60 since=2024-06-15T03:00:00Z for=5m0s; 2024-03-31 01:59:00 x2 paused for=1h0m0s; 2024-03-31 03:00:00 +"
+		ft, err := Parse(s, layout)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := ft.Format(layout); got != "2024-03-30 23:00:00 x60 since=2024-06-15T03:00:00Z for=5m0s; 2024-03-31 01:59:00 x2 paused for=1h0m0s; 2024-03-31 03:00:00 x1" {
+			t.Errorf("Format() = %q", got)
+		}
+	})
+
+	for name, s := range map[string]string{
+		"missing for":       "2024-03-30 23:00:00 x60; 2024-03-31 01:59:00",
+		"for in last step":  "2024-03-30 23:00:00 x60 for=5m; 2024-03-31 01:59:00 for=5m",
+		"for without steps": "2024-03-30 23:00:00 x60 for=5m",
+		"zero for":          "2024-03-30 23:00:00 x60 for=0s; 2024-03-31 01:59:00",
+		"invalid for":       "2024-03-30 23:00:00 x60 for=5; 2024-03-31 01:59:00",
+		"since in 2nd step": "2024-03-30 23:00:00 x60 for=5m; 2024-03-31 01:59:00 since=2024-06-15T03:00:00Z",
+		"invalid step":      "2024-03-30 23:00:00 x60 for=5m; tomorrow",
+		"no step":           "# nothing\n;",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(s, layout); !errors.Is(err, ErrInvalidFaketimeFileContent) {
+				t.Errorf("Parse(%q) error = %v, want %v", s, err, ErrInvalidFaketimeFileContent)
+			}
+		})
+	}
+}
+
+func TestClock_Script(t *testing.T) {
+	layout := "2006-01-02 15:04:05"
+	ft, err := Parse("2024-03-30 23:00:00 x60 for=5m; 2024-03-31 01:59:00 + for=1h; 2024-04-01 00:00:00", layout)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		elapsed time.Duration
+		want    time.Time
+	}{
+		{2 * time.Minute, time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC)},
+		{6 * time.Minute, time.Date(2024, 3, 31, 2, 0, 0, 0, time.UTC)},
+		{2 * time.Hour, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		step := *ft
+		step.Anchor = time.StdNow().Add(-tt.elapsed)
+		got := NewClock(&step).Now()
+		if diff := got.Sub(tt.want); diff < 0 || diff > 10*time.Second {
+			t.Errorf("Now() after %v = %v, want %v (+10s)", tt.elapsed, got, tt.want)
+		}
+	}
+
+	t.Run("Pause ends the script", func(t *testing.T) {
+		step := *ft
+		step.Anchor = time.StdNow().Add(-6 * time.Minute)
+		c := NewClock(&step)
+		c.Pause()
+		got := c.FakeTime()
+		if got.Then != nil || got.PausedRatio != 1 {
+			t.Errorf("FakeTime() = %+v, want paused at x1 without steps", got)
+		}
+	})
+
+	t.Run("Setup", func(t *testing.T) {
+		step := *ft
+		step.Anchor = time.StdNow().Add(-2 * time.Hour)
+		defer step.Setup(context.Background())()
+		if got, want := time.Now(), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("time.Now() = %v, want %v", got, want)
+		}
+	})
+}
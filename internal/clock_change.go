@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"slices"
+	"sync"
+	orig "time"
+)
+
+// clockHook is a callback registered by OnClockChange.
+type clockHook struct {
+	f func(old, new orig.Time)
+}
+
+var (
+	clockHooksMu sync.Mutex
+	clockHooks   []*clockHook
+)
+
+// OnClockChange calls f on each change by ChangeClock until the returned function is called.
+func OnClockChange(f func(old, new orig.Time)) func() {
+	h := &clockHook{f: f}
+	clockHooksMu.Lock()
+	defer clockHooksMu.Unlock()
+	clockHooks = append(clockHooks, h)
+	return func() {
+		clockHooksMu.Lock()
+		defer clockHooksMu.Unlock()
+		if i := slices.Index(clockHooks, h); i >= 0 {
+			clockHooks = slices.Delete(clockHooks, i, i+1)
+		}
+	}
+}
+
+// ChangeClock calls change, and then the hooks with the times which Peek returns
+// before and after it if they differ. Peek doesn't count the calls of clocks
+// such as the ones of testtime.Sequence.
+func ChangeClock(change func()) {
+	clockHooksMu.Lock()
+	hooks := slices.Clone(clockHooks)
+	clockHooksMu.Unlock()
+	if len(hooks) == 0 {
+		change()
+		return
+	}
+
+	old := Peek()
+	change()
+	new := Peek()
+	if old.Equal(new) {
+		return
+	}
+	for _, h := range hooks {
+		h.f(old, new)
+	}
+}
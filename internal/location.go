@@ -0,0 +1,10 @@
+package internal
+
+import (
+	"sync/atomic"
+	orig "time"
+)
+
+// Location replaces FixedLocation while it holds a location.
+// It's atomic because tests in other goroutines may read it while it's swapped.
+var Location atomic.Pointer[orig.Location]
@@ -1,7 +1,159 @@
 package internal
 
 import (
+	"fmt"
+	"slices"
+	"sync"
 	orig "time"
 )
 
 var NowFunc = orig.Now
+
+// Override describes an override of NowFunc.
+type Override struct {
+	// Owner describes who sets the override, such as a test name or a caller location.
+	Owner string
+	// Strict makes the restore panic if it's called while a strict override set after it is active.
+	// Otherwise the override can be restored in any order, which concurrent requests need.
+	Strict bool
+	// Peek returns the time which the clock returns next without counting the call.
+	// It's required for clocks which count the calls, and the clock is called if it's nil.
+	Peek func() orig.Time
+	// Info describes the clock for time.ClockInfo, and can be nil.
+	Info func() Info
+}
+
+// override is an entry of the stack of NowFunc overrides.
+type override struct {
+	Override
+	f func() orig.Time
+	// seq is the order in which the override is set.
+	seq uint64
+}
+
+// peek returns the time which f returns next without counting the call.
+func (o *override) peek() orig.Time {
+	if o.Peek != nil {
+		return o.Peek()
+	}
+	return o.f()
+}
+
+// Info describes an override of NowFunc for time.ClockInfo.
+type Info struct {
+	Source string
+	Spec   string
+	Ratio  float64
+	Anchor orig.Time
+}
+
+var (
+	overridesMu sync.Mutex
+	overrides   []*override
+	lastSeq     uint64
+)
+
+// SetNowFunc replaces NowFunc with f until the returned function is called.
+func SetNowFunc(f func() orig.Time, opts Override) func() {
+	o := &override{Override: opts, f: f}
+	ChangeClock(func() {
+		overridesMu.Lock()
+		defer overridesMu.Unlock()
+		lastSeq++
+		o.seq = lastSeq
+		overrides = append(overrides, o)
+		NowFunc = o.f
+	})
+	return func() {
+		overridesMu.Lock()
+		i := slices.Index(overrides, o)
+		if o.Strict && i >= 0 {
+			// Lenient overrides after it, such as the ones of concurrent requests, don't matter.
+			for _, later := range slices.Backward(overrides[i+1:]) {
+				if later.Strict {
+					overridesMu.Unlock()
+					panic(fmt.Sprintf("clock override by %s is restored before the override by %s set after it", o.Owner, later.Owner))
+				}
+			}
+		}
+		overridesMu.Unlock()
+		if i < 0 {
+			// Restored already.
+			return
+		}
+		ChangeClock(func() {
+			overridesMu.Lock()
+			defer overridesMu.Unlock()
+			if i := slices.Index(overrides, o); i >= 0 {
+				overrides = slices.Delete(overrides, i, i+1)
+			}
+			if len(overrides) == 0 {
+				NowFunc = orig.Now
+			} else {
+				NowFunc = overrides[len(overrides)-1].f
+			}
+		})
+	}
+}
+
+// Peek returns the time which NowFunc returns next without counting the call.
+func Peek() orig.Time {
+	overridesMu.Lock()
+	if len(overrides) == 0 {
+		overridesMu.Unlock()
+		return orig.Now()
+	}
+	top := overrides[len(overrides)-1]
+	overridesMu.Unlock()
+	// The clock is called without the lock because it may lock itself.
+	return top.peek()
+}
+
+// NowFuncOwners returns the owners of the active overrides from the oldest one.
+func NowFuncOwners() []string {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	owners := make([]string, len(overrides))
+	for i, o := range overrides {
+		owners[i] = o.Owner
+	}
+	return owners
+}
+
+// NowFuncMark returns a mark of the overrides set so far for StrictNowFuncOwners.
+func NowFuncMark() uint64 {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	return lastSeq
+}
+
+// StrictNowFuncOwners returns the owners of the active strict overrides set after mark
+// from the oldest one.
+func StrictNowFuncOwners(mark uint64) []string {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	var owners []string
+	for _, o := range overrides {
+		if o.Strict && o.seq > mark {
+			owners = append(owners, o.Owner)
+		}
+	}
+	return owners
+}
+
+// CurrentInfo returns the info of the active override, which is zero if it has no info.
+// It returns false if NowFunc isn't overridden.
+func CurrentInfo() (Info, bool) {
+	overridesMu.Lock()
+	if len(overrides) == 0 {
+		overridesMu.Unlock()
+		return Info{}, false
+	}
+	info := overrides[len(overrides)-1].Info
+	overridesMu.Unlock()
+	if info == nil {
+		return Info{}, true
+	}
+	// info is called without the lock because it may lock the clock.
+	return info(), true
+}
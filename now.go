@@ -16,6 +16,27 @@ var FixedLocation = func() *Location {
 	return FixedZone("Asia/Tokyo", 9*60*60)
 }()
 
+// CurrentLocation returns the location which Now uses.
+// It's FixedLocation unless testtime.SetLocation replaces it.
+func CurrentLocation() *Location {
+	if loc := internal.Location.Load(); loc != nil {
+		return loc
+	}
+	return FixedLocation
+}
+
+// OnClockChange calls f with the times before and after the clock of Now changes
+// until the returned function is called. The clock changes when testtime.SetTimeFunc,
+// testtime.Traveler.Set or faketime.FakeTime.Setup is called, and when they are restored.
+// The times are the ones which Now returns next, and they aren't counted as calls
+// by the clocks of testtime.Increment and testtime.Sequence.
+func OnClockChange(f func(old, new Time)) func() {
+	return internal.OnClockChange(func(old, new Time) {
+		loc := CurrentLocation()
+		f(old.In(loc), new.In(loc))
+	})
+}
+
 func NowWithLocation() Time {
-	return internal.NowFunc().In(FixedLocation)
+	return internal.NowFunc().In(CurrentLocation())
 }
@@ -0,0 +1,31 @@
+//go:build go1.25
+
+package testtime
+
+import (
+	"testing"
+	"testing/synctest"
+	orig "time"
+
+	"github.com/akm/time"
+)
+
+// Bubble runs f in a testing/synctest bubble whose clock starts at start.
+// time.Now returns start plus the time elapsed in the bubble, so it moves together
+// with time.Sleep, timers and tickers, which follow the virtual clock of the bubble.
+//
+// time.Now is overridden for the whole process while f runs, and goroutines outside
+// the bubble see start plus the real time elapsed since the epoch of the bubble in 2000.
+// So don't use it in parallel tests, or with goroutines which outlive the bubble.
+//
+// It needs testing/synctest of Go 1.25 or later, and skips the test with older Go.
+func Bubble(t *testing.T, start time.Time, f func(t *testing.T)) {
+	t.Helper()
+	synctest.Test(t, func(t *testing.T) {
+		epoch := orig.Now()
+		defer setTimeFunc(func() time.Time {
+			return start.Add(orig.Since(epoch))
+		}, nil, t.Name())()
+		f(t)
+	})
+}
@@ -0,0 +1,16 @@
+//go:build !go1.25
+
+package testtime
+
+import (
+	"testing"
+
+	"github.com/akm/time"
+)
+
+// Bubble skips the test because testing/synctest needs Go 1.25 or later.
+// See the one built with Go 1.25 or later.
+func Bubble(t *testing.T, start time.Time, f func(t *testing.T)) {
+	t.Helper()
+	t.Skip("testtime.Bubble needs Go 1.25 or later")
+}
@@ -0,0 +1,39 @@
+//go:build go1.25
+
+package testtime
+
+import (
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+
+	"github.com/akm/time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBubble(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+
+	Bubble(t, t0, func(t *testing.T) {
+		assert.Equal(t, t0, time.Now())
+
+		time.Sleep(time.Hour)
+		assert.Equal(t, t0.Add(time.Hour), time.Now())
+
+		timer := time.NewTimer(time.Minute)
+		<-timer.C
+		assert.Equal(t, t0.Add(time.Hour+time.Minute), time.Now())
+
+		var fired atomic.Bool
+		time.AfterFunc(time.Second, func() { fired.Store(true) })
+		synctest.Wait()
+		assert.False(t, fired.Load())
+		time.Sleep(time.Second)
+		synctest.Wait()
+		assert.True(t, fired.Load())
+		assert.Equal(t, t0.Add(time.Hour+time.Minute+time.Second), time.Now())
+	})
+
+	assert.NotEqual(t, t0, time.Now(), "time.Now should be restored after the bubble")
+}
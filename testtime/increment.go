@@ -0,0 +1,19 @@
+package testtime
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/akm/time"
+)
+
+// Increment makes time.Now return start on the first call and advance by step on each
+// following call until the test ends. It's safe for concurrent calls.
+func Increment(t *testing.T, start time.Time, step time.Duration) {
+	var calls atomic.Int64
+	t.Cleanup(setTimeFunc(func() time.Time {
+		return start.Add(time.Duration(calls.Add(1)-1) * step)
+	}, func() time.Time {
+		return start.Add(time.Duration(calls.Load()) * step)
+	}, t.Name()))
+}
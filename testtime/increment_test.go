@@ -0,0 +1,67 @@
+package testtime
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/akm/time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncrement(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+
+	t.Run("sequential", func(t *testing.T) {
+		Increment(t, t0, time.Millisecond)
+
+		assert.Equal(t, t0, time.Now())
+		assert.Equal(t, t0.Add(time.Millisecond), time.Now())
+		assert.Equal(t, t0.Add(2*time.Millisecond), time.Now())
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		Increment(t, t0, time.Second)
+
+		var wg sync.WaitGroup
+		results := make(chan time.Time, 100)
+		for range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results <- time.Now()
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		seen := map[time.Time]bool{}
+		for r := range results {
+			assert.False(t, seen[r], "duplicated time %v", r)
+			seen[r] = true
+		}
+		assert.Len(t, seen, 100)
+		assert.Equal(t, t0.Add(100*time.Second), time.Now())
+	})
+
+	assert.NotEqual(t, t0, time.Now(), "time.Now should be restored after the test")
+}
+
+func TestIncrementOnClockChange(t *testing.T) {
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+	defer time.OnClockChange(func(old, new time.Time) {})()
+
+	t.Run("increment", func(t *testing.T) {
+		Increment(t, start, time.Second)
+		assert.Equal(t, start, time.Now(), "hooks should not count the calls")
+		assert.Equal(t, start.Add(time.Second), time.Now())
+	})
+}
+
+func TestIncrementClockInfo(t *testing.T) {
+	start := time.StdNow().Add(-time.Hour)
+	Increment(t, start, time.Minute)
+
+	assert.InDelta(t, -time.Hour, time.ClockInfo().Offset, float64(time.Second))
+	assert.True(t, start.Equal(time.Now()), "ClockInfo should not count the calls")
+}
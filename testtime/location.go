@@ -0,0 +1,29 @@
+package testtime
+
+import (
+	"testing"
+
+	"github.com/akm/time"
+	"github.com/akm/time/internal"
+)
+
+// SetLocation makes time.Now return times in loc instead of time.FixedLocation
+// until the returned function is called.
+func SetLocation(loc *time.Location) func() {
+	backup := internal.Location.Swap(loc)
+	return func() {
+		internal.Location.Store(backup)
+	}
+}
+
+// InLocations runs fn in a subtest for each of locs with time.Now returning times in it.
+// fn must not call t.Parallel because the location is shared.
+func InLocations(t *testing.T, locs []*time.Location, fn func(t *testing.T, loc *time.Location)) {
+	t.Helper()
+	for _, loc := range locs {
+		t.Run(loc.String(), func(t *testing.T) {
+			defer SetLocation(loc)()
+			fn(t, loc)
+		})
+	}
+}
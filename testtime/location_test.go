@@ -0,0 +1,42 @@
+package testtime
+
+import (
+	"testing"
+
+	"github.com/akm/time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLocation(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+
+	restore := SetLocation(loc)
+	assert.Equal(t, loc, time.Now().Location())
+	assert.Equal(t, loc, time.CurrentLocation())
+	restore()
+
+	assert.Equal(t, time.FixedLocation, time.Now().Location())
+	assert.Equal(t, time.FixedLocation, time.CurrentLocation())
+}
+
+func TestInLocations(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 30, 0, 0, time.FixedLocation)
+	defer SetTime(&t0)()
+
+	locs := []*time.Location{time.UTC, time.FixedZone("UTC-5", -5*60*60), time.FixedLocation}
+	days := map[string]int{}
+	InLocations(t, locs, func(t *testing.T, loc *time.Location) {
+		now := time.Now()
+		assert.Equal(t, loc, now.Location())
+		assert.True(t, now.Equal(t0))
+		days[t.Name()] = now.Day()
+	})
+
+	assert.Equal(t, map[string]int{
+		"TestInLocations/UTC":        31,
+		"TestInLocations/UTC-5":      31,
+		"TestInLocations/Asia/Tokyo": 1,
+	}, days)
+	assert.Equal(t, time.FixedLocation, time.Now().Location())
+}
@@ -0,0 +1,57 @@
+package testtime
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+)
+
+// MainLayout is the layout of the time in specs which Main reads.
+var MainLayout = time.DateTime
+
+// Main runs the tests with the fake time given by spec, which is a faketime spec such as
+// "2024-03-31 09:00:00" or "2024-03-31 09:00:00 +". It's meant to be called from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		testtime.Main(m, "2024-03-31 09:00:00")
+//	}
+//
+// The -faketime flag or faketime.EnvSpec overrides spec, in this order, and an empty spec
+// runs the tests with the real time. Tests can still override the fake time with a Traveler.
+func Main(m *testing.M, spec string) {
+	flagSpec := flag.String("faketime", "", "faketime spec for the tests, which overrides "+faketime.EnvSpec)
+	flag.Parse()
+
+	source := time.ClockSourceTest
+	if s := os.Getenv(faketime.EnvSpec); s != "" {
+		spec, source = s, time.ClockSourceEnv
+	}
+	if *flagSpec != "" {
+		spec, source = *flagSpec, time.ClockSourceTest
+	}
+
+	if err := runMain(m, spec, source); err != nil {
+		fmt.Fprintf(os.Stderr, "testtime.Main: %v\n", err)
+		os.Exit(2)
+	}
+}
+
+// runMain calls m.Run with the fake time given by spec, which comes from source.
+// The exit code is left to the testing package, which uses the result of m.Run.
+func runMain(m interface{ Run() int }, spec string, source time.ClockSource) error {
+	if spec != "" {
+		ft, err := faketime.Parse(spec, MainLayout)
+		if err != nil {
+			return err
+		}
+		ft.Source = source
+		defer ft.Setup(context.Background())()
+	}
+	m.Run()
+	return nil
+}
@@ -0,0 +1,65 @@
+package testtime
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/akm/time"
+	"github.com/akm/time/faketime"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type runnerFunc func() int
+
+func (f runnerFunc) Run() int { return f() }
+
+func TestRunMain(t *testing.T) {
+	t.Run("frozen", func(t *testing.T) {
+		err := runMain(runnerFunc(func() int {
+			want := time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC)
+			assert.True(t, time.Now().Equal(want), "time.Now() = %v, want %v", time.Now(), want)
+
+			// Tests can still override it.
+			tv := NewTraveler()
+			tv.Set(want.AddDate(1, 0, 0))
+			assert.True(t, time.Now().Equal(want.AddDate(1, 0, 0)))
+			tv.Teardown()
+			assert.True(t, time.Now().Equal(want))
+			return 0
+		}), "2024-03-31 09:00:00", time.ClockSourceTest)
+		assert.NoError(t, err)
+		assert.NotEqual(t, 2024, time.Now().Year(), "time.Now should be restored after the tests")
+	})
+
+	t.Run("flowing", func(t *testing.T) {
+		err := runMain(runnerFunc(func() int {
+			start := time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC)
+			assert.WithinDuration(t, start, time.Now(), time.Second)
+
+			info := time.ClockInfo()
+			assert.Equal(t, time.ClockSourceEnv, info.Source)
+			assert.Equal(t, "2024-03-31 09:00:00 +", info.Spec)
+			assert.Equal(t, 1.0, info.Ratio)
+			assert.WithinDuration(t, time.StdNow(), info.Anchor, time.Second)
+			assert.InDelta(t, start.Sub(time.StdNow()), info.Offset, float64(time.Second))
+			return 0
+		}), "2024-03-31 09:00:00 +", time.ClockSourceEnv)
+		assert.NoError(t, err)
+	})
+
+	t.Run("empty spec", func(t *testing.T) {
+		err := runMain(runnerFunc(func() int {
+			assert.WithinDuration(t, time.StdNow(), time.Now(), time.Second)
+			return 0
+		}), "", time.ClockSourceTest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid spec", func(t *testing.T) {
+		called := false
+		err := runMain(runnerFunc(func() int { called = true; return 0 }), "tomorrow", time.ClockSourceTest)
+		assert.True(t, errors.Is(err, faketime.ErrInvalidFaketimeFileContent), "error = %v", err)
+		assert.False(t, called)
+	})
+}
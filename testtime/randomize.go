@@ -0,0 +1,128 @@
+package testtime
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	orig "time"
+
+	"github.com/akm/time"
+)
+
+// EnvSeed is the environment variable to reproduce the times which Randomize chooses.
+const EnvSeed = "TESTTIME_SEED"
+
+// DSTLocation is the location whose DST transitions Randomize chooses.
+// They are skipped if it can't be loaded, so the same seed may choose another time
+// on a machine without the time zone database.
+var DSTLocation = "America/New_York"
+
+// processSeed is used unless EnvSeed is set.
+var processSeed = sync.OnceValue(rand.Uint64)
+
+type randomRegion struct {
+	name     string
+	boundary func(r *rand.Rand) time.Time
+}
+
+var randomRegions = []randomRegion{
+	{"month end", func(r *rand.Rand) time.Time {
+		return time.Date(randomYear(r), time.Month(1+r.IntN(12))+1, 1, 0, 0, 0, 0, time.FixedLocation)
+	}},
+	{"Feb 29", func(r *rand.Rand) time.Time {
+		// Either the start or the end of Feb 29 in a leap year.
+		return time.Date(2000+4*r.IntN(25), time.February, 29+r.IntN(2), 0, 0, 0, 0, time.FixedLocation)
+	}},
+	{"year boundary", func(r *rand.Rand) time.Time {
+		return time.Date(randomYear(r), time.January, 1, 0, 0, 0, 0, time.FixedLocation)
+	}},
+	{"DST transition", func(r *rand.Rand) time.Time {
+		loc, err := time.LoadLocation(DSTLocation)
+		if err != nil {
+			return time.Time{}
+		}
+		transitions := dstTransitions(loc, randomYear(r))
+		if len(transitions) == 0 {
+			return time.Time{}
+		}
+		return transitions[r.IntN(len(transitions))]
+	}},
+	{"midnight", func(r *rand.Rand) time.Time {
+		return time.Date(randomYear(r), time.Month(1+r.IntN(12)), 1+r.IntN(28), 0, 0, 0, 0, time.FixedLocation)
+	}},
+}
+
+func randomYear(r *rand.Rand) int {
+	return 2000 + r.IntN(100)
+}
+
+// dstTransitions returns the instants when the offset of loc changes in the year.
+func dstTransitions(loc *time.Location, year int) []time.Time {
+	var result []time.Time
+	day := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	for day.Year() == year {
+		next := day.Add(24 * time.Hour)
+		_, off := day.Zone()
+		if _, nextOff := next.Zone(); nextOff != off {
+			lo, hi := day, next
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.Zone(); o == off {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			result = append(result, hi.Truncate(time.Second))
+		}
+		day = next
+	}
+	return result
+}
+
+// randomTime returns a time which depends only on seed and name, and the name of its region.
+func randomTime(seed uint64, name string) (time.Time, string) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	r := rand.New(rand.NewPCG(seed, h.Sum64()))
+
+	var region randomRegion
+	var boundary time.Time
+	for boundary.IsZero() {
+		region = randomRegions[r.IntN(len(randomRegions))]
+		boundary = region.boundary(r)
+	}
+	// Within a minute around the boundary so that it's crossed while the test runs.
+	now := boundary.Add(time.Duration(r.Int64N(int64(2*time.Minute))) - time.Minute).In(time.FixedLocation)
+	return now, region.name
+}
+
+// Randomize makes time.Now start at a random time near a boundary where time-dependent code
+// tends to break, such as month ends, Feb 29, year boundaries, DST transitions and midnight
+// in FixedLocation, and proceed at the real pace until the test ends.
+// The time depends on the seed and the name of the test. The seed is logged with the time,
+// and setting it to EnvSeed chooses the same time again.
+func Randomize(t *testing.T) time.Time {
+	t.Helper()
+
+	seed := processSeed()
+	if s := os.Getenv(EnvSeed); s != "" {
+		var err error
+		seed, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			t.Fatalf("testtime.Randomize: invalid %s %q: %v", EnvSeed, s, err)
+		}
+	}
+
+	now, region := randomTime(seed, t.Name())
+	t.Logf("testtime.Randomize: now is %s near %s, %s=%d to reproduce", now.Format(time.RFC3339Nano), region, EnvSeed, seed)
+
+	start := orig.Now()
+	t.Cleanup(setTimeFunc(func() time.Time {
+		return now.Add(orig.Since(start))
+	}, nil, t.Name()))
+	return now
+}
@@ -0,0 +1,75 @@
+package testtime
+
+import (
+	"testing"
+
+	"github.com/akm/time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomize(t *testing.T) {
+	t.Setenv(EnvSeed, "12345")
+
+	got := Randomize(t)
+	want, _ := randomTime(12345, t.Name())
+	assert.Equal(t, want, got)
+	assert.WithinDuration(t, got, time.Now(), time.Second)
+}
+
+func TestRandomTime(t *testing.T) {
+	t1, r1 := randomTime(1, "TestA")
+	t2, r2 := randomTime(1, "TestA")
+	assert.Equal(t, t1, t2)
+	assert.Equal(t, r1, r2)
+
+	loc, err := time.LoadLocation(DSTLocation)
+	if err != nil {
+		t.Logf("DST transitions are not checked: %v", err)
+	}
+
+	regions := map[string]bool{}
+	for i := range 200 {
+		got, region := randomTime(uint64(i), "TestA")
+		regions[region] = true
+
+		var boundaries []time.Time
+		if region == "DST transition" {
+			if loc == nil {
+				continue
+			}
+			boundaries = dstTransitions(loc, got.In(loc).Year())
+		} else {
+			midnight := time.Date(got.Year(), got.Month(), got.Day(), 0, 0, 0, 0, time.FixedLocation)
+			boundaries = []time.Time{midnight, midnight.AddDate(0, 0, 1)}
+		}
+		near := false
+		for _, b := range boundaries {
+			if d := got.Sub(b); -time.Minute <= d && d < time.Minute {
+				near = true
+			}
+		}
+		assert.True(t, near, "seed %d: %v is not near a boundary of %s", i, got, region)
+	}
+	assert.Len(t, regions, len(randomRegions), "every region should be chosen, got %v", regions)
+}
+
+func TestDSTTransitions(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	assert.Equal(t, []time.Time{
+		time.Date(2024, time.March, 10, 7, 0, 0, 0, time.UTC),
+		time.Date(2024, time.November, 3, 6, 0, 0, 0, time.UTC),
+	}, utc(dstTransitions(loc, 2024)))
+	assert.Empty(t, dstTransitions(time.FixedLocation, 2024))
+}
+
+func utc(ts []time.Time) []time.Time {
+	result := make([]time.Time, len(ts))
+	for i, t := range ts {
+		result[i] = t.UTC()
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package testtime
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/akm/time"
+)
+
+// EnvUpdateGolden is the environment variable to make Scrubber.Golden write golden files.
+const EnvUpdateGolden = "TESTTIME_UPDATE_GOLDEN"
+
+// ScrubLayouts are the layouts which Scrubber looks for.
+var ScrubLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	time.DateTime,
+	time.RFC1123,
+	time.RFC1123Z,
+}
+
+// Scrubber replaces the fake time and the times at Offsets from it in text
+// with placeholders such as <NOW> and <NOW+1h>, so that the text can be compared with golden files.
+// The times are looked for in ScrubLayouts in time.CurrentLocation, FixedLocation and UTC,
+// and as Unix seconds and milliseconds.
+type Scrubber struct {
+	Now     time.Time
+	Offsets []time.Duration
+}
+
+// NewScrubber returns a Scrubber for the current time, which is meant to be frozen by SetTime or a Traveler.
+func NewScrubber(offsets ...time.Duration) *Scrubber {
+	return &Scrubber{Now: time.Now(), Offsets: offsets}
+}
+
+type replacement struct {
+	old, new string
+}
+
+func (s *Scrubber) replacements() []replacement {
+	var result []replacement
+	for _, offset := range append([]time.Duration{0}, s.Offsets...) {
+		placeholder := placeholder(offset, "")
+		t := s.Now.Add(offset)
+		for _, loc := range []*time.Location{time.CurrentLocation(), time.FixedLocation, time.UTC} {
+			for _, layout := range ScrubLayouts {
+				result = append(result, replacement{t.In(loc).Format(layout), placeholder})
+			}
+		}
+		// GMT instead of UTC as net/http writes.
+		result = append(result, replacement{t.UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT"), placeholder})
+	}
+	// Longer ones first not to leave a part of them.
+	slices.SortStableFunc(result, func(a, b replacement) int { return cmp.Compare(len(b.old), len(a.old)) })
+	return result
+}
+
+// placeholder returns such as <NOW>, <NOW+1h> or <NOW-30m:unix>.
+func placeholder(offset time.Duration, kind string) string {
+	s := "NOW"
+	if offset > 0 {
+		s += "+" + formatOffset(offset)
+	} else if offset < 0 {
+		s += "-" + formatOffset(-offset)
+	}
+	if kind != "" {
+		s += ":" + kind
+	}
+	return "<" + s + ">"
+}
+
+// formatOffset formats d without trailing zero units such as 1h instead of 1h0m0s.
+func formatOffset(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+var numberPattern = regexp.MustCompile(`\d+`)
+
+// Scrub returns text with the times replaced by placeholders.
+func (s *Scrubber) Scrub(text string) string {
+	for _, r := range s.replacements() {
+		text = strings.ReplaceAll(text, r.old, r.new)
+	}
+
+	numbers := map[string]string{}
+	for _, offset := range append([]time.Duration{0}, s.Offsets...) {
+		t := s.Now.Add(offset)
+		numbers[strconv.FormatInt(t.Unix(), 10)] = placeholder(offset, "unix")
+		numbers[strconv.FormatInt(t.UnixMilli(), 10)] = placeholder(offset, "unixmilli")
+	}
+	return numberPattern.ReplaceAllStringFunc(text, func(n string) string {
+		if p, ok := numbers[n]; ok {
+			return p
+		}
+		return n
+	})
+}
+
+// Golden compares got scrubbed by Scrub with the golden file at path.
+// If EnvUpdateGolden is set, it writes the scrubbed got to the file instead.
+func (s *Scrubber) Golden(t *testing.T, path string, got []byte) {
+	t.Helper()
+
+	scrubbed := s.Scrub(string(got))
+	if os.Getenv(EnvUpdateGolden) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("testtime: failed to create the directory of %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, []byte(scrubbed), 0o644); err != nil {
+			t.Fatalf("testtime: failed to write %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("testtime: failed to read %s, run with %s=1 to create it: %v", path, EnvUpdateGolden, err)
+	}
+	if scrubbed != string(want) {
+		t.Errorf("testtime: output differs from %s, run with %s=1 to update it\n--- got:\n%s\n--- want:\n%s", path, EnvUpdateGolden, scrubbed, string(want))
+	}
+}
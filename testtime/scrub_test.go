@@ -0,0 +1,69 @@
+package testtime
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/akm/time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrubber_Scrub(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 123000000, time.UTC)
+	defer SetTime(&now)()
+
+	s := NewScrubber(time.Hour, -30*time.Minute)
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"RFC3339Nano in UTC", `{"created_at":"2024-01-02T15:04:05.123Z"}`, `{"created_at":"<NOW>"}`},
+		{"RFC3339Nano in FixedLocation", `{"created_at":"2024-01-03T00:04:05.123+09:00"}`, `{"created_at":"<NOW>"}`},
+		{"RFC3339 without fraction", `2024-01-03T00:04:05+09:00`, `<NOW>`},
+		{"DateTime", `<td>2024-01-03 00:04:05</td><td>2024-01-02 15:04:05</td>`, `<td><NOW></td><td><NOW></td>`},
+		{"HTTP date", `Date: Tue, 02 Jan 2024 15:04:05 GMT`, `Date: <NOW>`},
+		{"offsets", `"expires":"2024-01-02T16:04:05.123Z","since":"2024-01-02T14:34:05.123Z"`, `"expires":"<NOW+1h>","since":"<NOW-30m>"`},
+		{"unix", `{"iat":` + strconv.FormatInt(now.Unix(), 10) + `,"exp":` + strconv.FormatInt(now.Add(time.Hour).UnixMilli(), 10) + `}`, `{"iat":<NOW:unix>,"exp":<NOW+1h:unixmilli>}`},
+		{"other times are kept", `2024-01-02T15:04:06Z 1704207846`, `2024-01-02T15:04:06Z 1704207846`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.Scrub(tt.in))
+		})
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	assert.Equal(t, "1h", formatOffset(time.Hour))
+	assert.Equal(t, "1h30m", formatOffset(90*time.Minute))
+	assert.Equal(t, "1m30s", formatOffset(90*time.Second))
+	assert.Equal(t, "24h", formatOffset(24*time.Hour))
+	assert.Equal(t, "1.5s", formatOffset(1500*time.Millisecond))
+}
+
+func TestScrubber_Golden(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	defer SetTime(&now)()
+
+	path := filepath.Join(t.TempDir(), "testdata", "out.golden")
+	s := NewScrubber()
+	got := []byte(`{"now":"` + time.Now().Format(time.RFC3339Nano) + `"}`)
+
+	t.Setenv(EnvUpdateGolden, "1")
+	s.Golden(t, path, got)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `{"now":"<NOW>"}`, string(content))
+
+	t.Setenv(EnvUpdateGolden, "")
+	// Another fake time gives the same output after scrubbing.
+	now = now.Add(24 * time.Hour)
+	NewScrubber().Golden(t, path, []byte(`{"now":"`+time.Now().Format(time.RFC3339Nano)+`"}`))
+}
@@ -0,0 +1,59 @@
+package testtime
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/akm/time"
+)
+
+// Sequence makes time.Now return times one by one until the test ends.
+// The test fails if time.Now is called more times than len(times), in which case
+// the last time is returned again, or if some of times are not returned by the end of the test.
+func Sequence(t *testing.T, times ...time.Time) {
+	t.Helper()
+	sequence(t, times)
+}
+
+// reporter is the part of testing.TB which sequence uses.
+type reporter interface {
+	Errorf(format string, args ...any)
+	Cleanup(func())
+	Name() string
+}
+
+func sequence(t reporter, times []time.Time) {
+	var mu sync.Mutex
+	calls := 0
+	restore := setTimeFunc(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls > len(times) {
+			t.Errorf("testtime.Sequence: time.Now is called %d times, but only %d times are given", calls, len(times))
+			if len(times) == 0 {
+				return time.Time{}
+			}
+			return times[len(times)-1]
+		}
+		return times[calls-1]
+	}, func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case len(times) == 0:
+			return time.Time{}
+		case calls < len(times):
+			return times[calls]
+		}
+		return times[len(times)-1]
+	}, t.Name())
+	t.Cleanup(func() {
+		restore()
+		mu.Lock()
+		defer mu.Unlock()
+		if calls < len(times) {
+			t.Errorf("testtime.Sequence: time.Now is called %d times, but %d times are given", calls, len(times))
+		}
+	})
+}
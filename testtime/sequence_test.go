@@ -0,0 +1,90 @@
+package testtime
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/akm/time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequence(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+	t1 := t0.Add(time.Minute)
+	t2 := t0.Add(-time.Hour)
+
+	t.Run("all times are used", func(t *testing.T) {
+		Sequence(t, t0, t1, t2)
+
+		assert.Equal(t, t0, time.Now())
+		assert.Equal(t, t1, time.Now())
+		assert.Equal(t, t2, time.Now())
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		r := &fakeReporter{}
+		sequence(r, []time.Time{t0})
+
+		assert.Equal(t, t0, time.Now())
+		assert.Equal(t, t0, time.Now())
+		r.cleanup()
+		assert.Equal(t, []string{"testtime.Sequence: time.Now is called 2 times, but only 1 times are given"}, r.errors)
+	})
+
+	t.Run("left over", func(t *testing.T) {
+		r := &fakeReporter{}
+		sequence(r, []time.Time{t0, t1})
+
+		assert.Equal(t, t0, time.Now())
+		r.cleanup()
+		assert.Equal(t, []string{"testtime.Sequence: time.Now is called 1 times, but 2 times are given"}, r.errors)
+	})
+
+	assert.NotEqual(t, t0, time.Now(), "time.Now should be restored after the test")
+}
+
+// fakeReporter records failures instead of failing the test.
+type fakeReporter struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (r *fakeReporter) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *fakeReporter) Name() string {
+	return "fakeReporter"
+}
+
+func (r *fakeReporter) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *fakeReporter) cleanup() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestSequenceOnClockChange(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+	t1 := t0.Add(time.Hour)
+
+	var news []time.Time
+	defer time.OnClockChange(func(old, new time.Time) {
+		news = append(news, new)
+	})()
+
+	r := &fakeReporter{}
+	sequence(r, []time.Time{t0, t1})
+	assert.Equal(t, t0, time.Now(), "hooks should not consume the times")
+	assert.Equal(t, t1, time.Now())
+	r.cleanup()
+	assert.Empty(t, r.errors)
+	if assert.Len(t, news, 2) {
+		assert.Equal(t, t0, news[0])
+		assert.NotEqual(t, t1, news[1], "the real time should be the new one on restore")
+	}
+}
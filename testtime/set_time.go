@@ -1,18 +1,84 @@
 package testtime
 
 import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
 	"github.com/akm/time"
 	"github.com/akm/time/internal"
 )
 
+// SetTimeFunc makes time.Now call f until the returned function is called.
+// Overrides are stacked, and the returned function panics if it's called
+// while an override set after it by this package is still active.
 func SetTimeFunc(f func() time.Time) func() {
-	var backup func() time.Time
-	internal.NowFunc, backup = f, internal.NowFunc
-	return func() {
-		internal.NowFunc = backup
-	}
+	return setTimeFunc(f, nil, "")
 }
 
 func SetTime(v *time.Time) func() {
 	return SetTimeFunc(func() time.Time { return *v })
 }
+
+// setTimeFunc is SetTimeFunc with the name of the test which owns the override.
+// peek returns the time which f returns next without counting the call, and it's
+// required if f counts the calls.
+func setTimeFunc(f, peek func() time.Time, name string) func() {
+	owner := callerLocation()
+	if name != "" {
+		owner = name + " (" + owner + ")"
+	}
+	return internal.SetNowFunc(f, internal.Override{Owner: owner, Strict: true, Peek: peek})
+}
+
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// callerLocation returns the location of the first caller outside this package except tests.
+func callerLocation() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// AssertRestored fails the test if overrides of time.Now set by this package are still active.
+// Call it at the end of a test to catch leaked overrides.
+//
+// It also fails the test if overrides set after it are left when the test and its cleanups finish.
+// Overrides restored by t.Cleanup, such as the ones of Increment and Sequence, are active until
+// then, so call it at the beginning of such tests instead.
+// Fake times set up by faketime, such as the one of Main, are not checked.
+func AssertRestored(t *testing.T) {
+	t.Helper()
+	assertRestored(t)
+}
+
+func assertRestored(t interface {
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}) {
+	report := func(owners []string) {
+		if len(owners) > 0 {
+			t.Errorf("testtime: time.Now is still overridden by:\n\t%s", strings.Join(owners, "\n\t"))
+		}
+	}
+	report(internal.StrictNowFuncOwners(0))
+
+	mark := internal.NowFuncMark()
+	// The cleanup registered first runs last, after the ones which restore overrides.
+	t.Cleanup(func() {
+		report(internal.StrictNowFuncOwners(mark))
+	})
+}
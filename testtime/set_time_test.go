@@ -1,9 +1,11 @@
 package testtime
 
 import (
+	"context"
 	"testing"
 
 	"github.com/akm/time"
+	"github.com/akm/time/faketime"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -30,3 +32,142 @@ func TestSetTime(t *testing.T) {
 	*now = t1
 	assert.Equal(t, t1, time.Now())
 }
+
+func TestSetTimeFuncNested(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+	t1 := time.Date(2020, 2, 3, 4, 5, 6, 0, time.FixedLocation)
+	AssertRestored(t)
+
+	restore0 := SetTime(&t0)
+	restore1 := SetTime(&t1)
+	assert.Equal(t, t1, time.Now())
+
+	restore1()
+	assert.Equal(t, t0, time.Now())
+	restore1()
+	assert.Equal(t, t0, time.Now(), "restoring twice should do nothing")
+
+	restore0()
+	assert.NotEqual(t, t0, time.Now())
+}
+
+func TestSetTimeFuncOutOfOrder(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+	t1 := time.Date(2020, 2, 3, 4, 5, 6, 0, time.FixedLocation)
+	AssertRestored(t)
+
+	restore0 := SetTime(&t0)
+	restore1 := SetTime(&t1)
+
+	defer func() {
+		r := recover()
+		if assert.NotNil(t, r) {
+			assert.Regexp(t, `^clock override by set_time_test\.go:\d+ is restored before the override by set_time_test\.go:\d+ set after it$`, r)
+		}
+		assert.Equal(t, t1, time.Now())
+		restore1()
+		restore0()
+	}()
+	restore0()
+}
+
+func TestSetTimeFuncOutOfOrderWithFaketime(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+	ft := faketime.FakeTime{Time: time.Date(2020, 2, 3, 4, 5, 6, 0, time.FixedLocation)}
+	AssertRestored(t)
+
+	restore := SetTime(&t0)
+	// A fake time of a concurrent request, which is restored in any order.
+	teardown := ft.Setup(context.Background())
+
+	assert.NotPanics(t, restore)
+	assert.Equal(t, ft.Time, time.Now())
+	teardown()
+	assert.NotEqual(t, t0, time.Now())
+}
+
+func TestAssertRestored(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+
+	t.Run("restored", func(t *testing.T) {
+		r := &fakeReporter{}
+		assertRestored(r)
+		sequence(r, []time.Time{t0})
+		assert.Equal(t, t0, time.Now())
+		r.cleanup()
+		assert.Empty(t, r.errors)
+	})
+
+	t.Run("leaked", func(t *testing.T) {
+		r := &fakeReporter{}
+		assertRestored(r)
+		restore := SetTime(&t0)
+		r.cleanup()
+		restore()
+		if assert.Len(t, r.errors, 1) {
+			assert.Regexp(t, `^testtime: time\.Now is still overridden by:\n\tset_time_test\.go:\d+$`, r.errors[0])
+		}
+	})
+
+	t.Run("leaked before", func(t *testing.T) {
+		restore := SetTime(&t0)
+		r := &fakeReporter{}
+		assertRestored(r)
+		restore()
+		r.cleanup()
+		if assert.Len(t, r.errors, 1, "the leak should be reported when it's called, not by the cleanup") {
+			assert.Regexp(t, `^testtime: time\.Now is still overridden by:\n\tset_time_test\.go:\d+$`, r.errors[0])
+		}
+	})
+
+	t.Run("faketime", func(t *testing.T) {
+		// A fake time which is left like the one of Main.
+		ft := faketime.FakeTime{Time: t0}
+		teardown := ft.Setup(context.Background())
+		defer teardown()
+		r := &fakeReporter{}
+		assertRestored(r)
+		r.cleanup()
+		assert.Empty(t, r.errors, "fake times should be ignored")
+	})
+}
+
+func TestSetTimeFuncOnClockChange(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+	t1 := time.Date(2020, 2, 3, 4, 5, 6, 0, time.FixedLocation)
+
+	type change struct{ old, new time.Time }
+	var changes []change
+	unregister := time.OnClockChange(func(old, new time.Time) {
+		changes = append(changes, change{old, new})
+	})
+
+	restore0 := SetTime(&t0)
+	restore1 := SetTime(&t1)
+	restore1()
+	if assert.Len(t, changes, 3) {
+		assert.Equal(t, t0, changes[0].new)
+		assert.Equal(t, change{t0, t1}, changes[1])
+		assert.Equal(t, change{t1, t0}, changes[2])
+	}
+
+	unregister()
+	restore0()
+	assert.Len(t, changes, 3, "hooks should not be called after unregistering")
+}
+
+func TestSetTimeFuncClockInfo(t *testing.T) {
+	info := time.ClockInfo()
+	assert.Equal(t, time.ClockStatus{Source: time.ClockSourceReal, Ratio: 1}, info)
+	assert.False(t, info.Faked())
+
+	t0 := time.StdNow().Add(-time.Hour)
+	defer SetTime(&t0)()
+
+	info = time.ClockInfo()
+	assert.True(t, info.Faked())
+	assert.Equal(t, time.ClockSourceTest, info.Source)
+	assert.Empty(t, info.Spec)
+	assert.Zero(t, info.Ratio)
+	assert.InDelta(t, -time.Hour, info.Offset, float64(time.Second))
+}
@@ -0,0 +1,62 @@
+package testtime
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/akm/time"
+)
+
+// Sweep runs f in a subtest at each instant from from to to by step with time.Now frozen at it.
+// Calendar edge cases between from and to are added to the instants: the start and the last
+// day of each month including leap days, the last nanosecond of each month, and the DST
+// transitions in DSTLocation with the nanosecond before them. The boundaries are in FixedLocation.
+// f must not call t.Parallel because the clock is shared.
+func Sweep(t *testing.T, from, to time.Time, step time.Duration, f func(t *testing.T, now time.Time)) {
+	t.Helper()
+	if step <= 0 {
+		t.Fatalf("testtime.Sweep: step must be positive: %v", step)
+	}
+
+	var instants []time.Time
+	for now := from; !now.After(to); now = now.Add(step) {
+		instants = append(instants, now)
+	}
+	instants = append(instants, edgeCases(from, to)...)
+	slices.SortStableFunc(instants, func(a, b time.Time) int { return a.Compare(b) })
+	instants = slices.CompactFunc(instants, func(a, b time.Time) bool { return a.Equal(b) })
+
+	for _, now := range instants {
+		t.Run(now.In(time.FixedLocation).Format(time.RFC3339Nano), func(t *testing.T) {
+			defer setTimeFunc(func() time.Time { return now }, nil, t.Name())()
+			f(t, now)
+		})
+	}
+}
+
+// edgeCases returns the calendar edge cases between from and to.
+func edgeCases(from, to time.Time) []time.Time {
+	var result []time.Time
+	add := func(ts ...time.Time) {
+		for _, t := range ts {
+			if !t.Before(from) && !t.After(to) {
+				result = append(result, t)
+			}
+		}
+	}
+
+	start := from.In(time.FixedLocation)
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.FixedLocation); !month.After(to); month = month.AddDate(0, 1, 0) {
+		next := month.AddDate(0, 1, 0)
+		add(month, next.AddDate(0, 0, -1), next.Add(-time.Nanosecond))
+	}
+
+	if loc, err := time.LoadLocation(DSTLocation); err == nil {
+		for year := from.In(loc).Year(); year <= to.In(loc).Year(); year++ {
+			for _, tr := range dstTransitions(loc, year) {
+				add(tr.Add(-time.Nanosecond), tr)
+			}
+		}
+	}
+	return result
+}
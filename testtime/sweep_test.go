@@ -0,0 +1,49 @@
+package testtime
+
+import (
+	"testing"
+
+	"github.com/akm/time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSweep(t *testing.T) {
+	from := time.Date(2024, 2, 28, 0, 0, 0, 0, time.FixedLocation)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.FixedLocation)
+
+	var got []time.Time
+	var names []string
+	Sweep(t, from, to, 24*time.Hour, func(t *testing.T, now time.Time) {
+		assert.Equal(t, now, time.Now())
+		got = append(got, now)
+		names = append(names, t.Name())
+	})
+
+	assert.Equal(t, []time.Time{
+		from,
+		time.Date(2024, 2, 29, 0, 0, 0, 0, time.FixedLocation),
+		time.Date(2024, 2, 29, 23, 59, 59, 999999999, time.FixedLocation),
+		to,
+	}, got)
+	assert.Equal(t, "TestSweep/2024-02-29T23:59:59.999999999+09:00", names[2])
+	assert.NotEqual(t, to, time.Now(), "time.Now should be restored after the sweep")
+}
+
+func TestSweep_DST(t *testing.T) {
+	loc, err := time.LoadLocation(DSTLocation)
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	from := time.Date(2024, 3, 10, 0, 0, 0, 0, loc)
+	to := time.Date(2024, 3, 10, 12, 0, 0, 0, loc)
+
+	var got []time.Time
+	Sweep(t, from, to, 6*time.Hour, func(t *testing.T, now time.Time) {
+		got = append(got, now.UTC())
+	})
+
+	transition := time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)
+	assert.Contains(t, got, transition)
+	assert.Contains(t, got, transition.Add(-time.Nanosecond))
+}
@@ -0,0 +1,118 @@
+// Package testtimeassert provides assertions for times.
+// Failure messages show the times in time.FixedLocation with their difference.
+package testtimeassert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akm/time"
+)
+
+// TestingT is the part of testing.TB which the assertions use.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+type tHelper interface {
+	Helper()
+}
+
+// WithinDuration asserts that actual is within delta of expected.
+func WithinDuration(t TestingT, expected, actual time.Time, delta time.Duration, msgAndArgs ...any) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	if diff := actual.Sub(expected); -delta <= diff && diff <= delta {
+		return true
+	}
+	return fail(t, fmt.Sprintf("times are not within %v", delta), expected, actual, msgAndArgs)
+}
+
+// SameDay asserts that expected and actual are on the same date in loc.
+func SameDay(t TestingT, loc *time.Location, expected, actual time.Time, msgAndArgs ...any) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	ey, em, ed := expected.In(loc).Date()
+	ay, am, ad := actual.In(loc).Date()
+	if ey == ay && em == am && ed == ad {
+		return true
+	}
+	return fail(t, fmt.Sprintf("times are not on the same day in %s", loc), expected, actual, msgAndArgs)
+}
+
+// SameInstant asserts that expected and actual are the same instant.
+// Unlike assert.Equal of testify, it ignores their locations and monotonic clock readings.
+func SameInstant(t TestingT, expected, actual time.Time, msgAndArgs ...any) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	if expected.Equal(actual) {
+		return true
+	}
+	return fail(t, "times are not the same instant", expected, actual, msgAndArgs)
+}
+
+// Before asserts that actual is before expected.
+func Before(t TestingT, expected, actual time.Time, msgAndArgs ...any) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	if actual.Before(expected) {
+		return true
+	}
+	return fail(t, "actual is not before expected", expected, actual, msgAndArgs)
+}
+
+// After asserts that actual is after expected.
+func After(t TestingT, expected, actual time.Time, msgAndArgs ...any) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	if actual.After(expected) {
+		return true
+	}
+	return fail(t, "actual is not after expected", expected, actual, msgAndArgs)
+}
+
+// IsNow asserts that actual is within tolerance of time.Now, which follows the fake clock if any.
+func IsNow(t TestingT, actual time.Time, tolerance time.Duration, msgAndArgs ...any) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	now := time.Now()
+	if diff := actual.Sub(now); -tolerance <= diff && diff <= tolerance {
+		return true
+	}
+	return fail(t, fmt.Sprintf("time is not now within %v", tolerance), now, actual, msgAndArgs)
+}
+
+func fail(t TestingT, summary string, expected, actual time.Time, msgAndArgs []any) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	var b strings.Builder
+	b.WriteString(summary)
+	fmt.Fprintf(&b, "\n\texpected: %s", expected.In(time.FixedLocation).Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "\n\tactual  : %s", actual.In(time.FixedLocation).Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "\n\tdiff    : %v", actual.Sub(expected))
+	if msg := message(msgAndArgs); msg != "" {
+		fmt.Fprintf(&b, "\n\tmessage : %s", msg)
+	}
+	t.Errorf("%s", b.String())
+	return false
+}
+
+func message(msgAndArgs []any) string {
+	switch len(msgAndArgs) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprint(msgAndArgs[0])
+	}
+	if format, ok := msgAndArgs[0].(string); ok {
+		return fmt.Sprintf(format, msgAndArgs[1:]...)
+	}
+	return fmt.Sprint(msgAndArgs...)
+}
@@ -0,0 +1,123 @@
+package testtimeassert
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/akm/time"
+	"github.com/akm/time/testtime"
+)
+
+// recorder records failures instead of failing the test.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	t0 := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		assert  func(t TestingT) bool
+		wantErr string // empty for success
+	}{
+		{
+			name:   "WithinDuration",
+			assert: func(t TestingT) bool { return WithinDuration(t, t0, t0.Add(-time.Second), time.Second) },
+		},
+		{
+			name:   "WithinDuration fails",
+			assert: func(t TestingT) bool { return WithinDuration(t, t0, t0.Add(2*time.Second), time.Second, "user %d", 1) },
+			wantErr: "times are not within 1s" +
+				"\n\texpected: 2024-01-03T00:04:05+09:00" +
+				"\n\tactual  : 2024-01-03T00:04:07+09:00" +
+				"\n\tdiff    : 2s" +
+				"\n\tmessage : user 1",
+		},
+		{
+			name:   "SameDay",
+			assert: func(t TestingT) bool { return SameDay(t, time.UTC, t0, t0.Add(8*time.Hour)) },
+		},
+		{
+			name:   "SameDay fails in other location",
+			assert: func(t TestingT) bool { return SameDay(t, time.FixedLocation, t0, t0.Add(-time.Hour)) },
+			wantErr: "times are not on the same day in Asia/Tokyo" +
+				"\n\texpected: 2024-01-03T00:04:05+09:00" +
+				"\n\tactual  : 2024-01-02T23:04:05+09:00" +
+				"\n\tdiff    : -1h0m0s",
+		},
+		{
+			name:   "SameInstant ignores location",
+			assert: func(t TestingT) bool { return SameInstant(t, t0, t0.In(time.FixedLocation)) },
+		},
+		{
+			name:   "SameInstant fails",
+			assert: func(t TestingT) bool { return SameInstant(t, t0, t0.Add(time.Nanosecond)) },
+			wantErr: "times are not the same instant" +
+				"\n\texpected: 2024-01-03T00:04:05+09:00" +
+				"\n\tactual  : 2024-01-03T00:04:05.000000001+09:00" +
+				"\n\tdiff    : 1ns",
+		},
+		{
+			name:   "Before",
+			assert: func(t TestingT) bool { return Before(t, t0, t0.Add(-time.Minute)) },
+		},
+		{
+			name:   "Before fails for the same time",
+			assert: func(t TestingT) bool { return Before(t, t0, t0) },
+			wantErr: "actual is not before expected" +
+				"\n\texpected: 2024-01-03T00:04:05+09:00" +
+				"\n\tactual  : 2024-01-03T00:04:05+09:00" +
+				"\n\tdiff    : 0s",
+		},
+		{
+			name:   "After",
+			assert: func(t TestingT) bool { return After(t, t0, t0.Add(time.Minute)) },
+		},
+		{
+			name:   "After fails",
+			assert: func(t TestingT) bool { return After(t, t0, t0.Add(-time.Minute), "message") },
+			wantErr: "actual is not after expected" +
+				"\n\texpected: 2024-01-03T00:04:05+09:00" +
+				"\n\tactual  : 2024-01-03T00:03:05+09:00" +
+				"\n\tdiff    : -1m0s" +
+				"\n\tmessage : message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			ok := tt.assert(r)
+			if tt.wantErr == "" {
+				if !ok || len(r.errors) > 0 {
+					t.Errorf("assertion should succeed, errors: %v", r.errors)
+				}
+				return
+			}
+			if ok {
+				t.Fatal("assertion should fail")
+			}
+			if len(r.errors) != 1 || r.errors[0] != tt.wantErr {
+				t.Errorf("errors = %q, want %q", r.errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsNow(t *testing.T) {
+	t0 := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	defer testtime.SetTime(&t0)()
+
+	r := &recorder{}
+	if !IsNow(r, t0.Add(time.Second), time.Second) {
+		t.Errorf("IsNow should succeed, errors: %v", r.errors)
+	}
+	if IsNow(r, time.StdNow(), time.Second) {
+		t.Error("IsNow should compare with the fake clock")
+	}
+}
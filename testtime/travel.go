@@ -1,6 +1,9 @@
 package testtime
 
-import "github.com/akm/time"
+import (
+	"github.com/akm/time"
+	"github.com/akm/time/internal"
+)
 
 type Traveler struct {
 	now      *time.Time
@@ -17,5 +20,5 @@ func (tv *Traveler) Teardown() {
 }
 
 func (tv *Traveler) Set(v time.Time) {
-	*tv.now = v
+	internal.ChangeClock(func() { *tv.now = v })
 }
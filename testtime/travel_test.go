@@ -19,3 +19,22 @@ func TestTravel(t *testing.T) {
 	actual := time.Now()
 	assert.Equal(t, base.Add(2*time.Hour), actual)
 }
+
+func TestTravelOnClockChange(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedLocation)
+	t1 := t0.Add(2 * time.Hour)
+
+	tv := NewTraveler()
+	defer tv.Teardown()
+	tv.Set(t0)
+
+	type change struct{ old, new time.Time }
+	var changes []change
+	defer time.OnClockChange(func(old, new time.Time) {
+		changes = append(changes, change{old, new})
+	})()
+
+	tv.Set(t1)
+	tv.Set(t1)
+	assert.Equal(t, []change{{t0, t1}}, changes, "setting the same time should not be a change")
+}